@@ -0,0 +1,35 @@
+package ip2location
+
+import "time"
+
+// Age returns how long ago the database was built, from the build date in
+// its header. The header stores a two-digit year, which is taken to be in
+// 2000-2099 unless WithCenturyBase says otherwise. Age returns 0 if the
+// stored date is not a valid calendar date.
+func (db *DB) Age() time.Duration {
+	built, ok := db.buildDate()
+	if !ok {
+		return 0
+	}
+	return time.Since(built)
+}
+
+// build date from the header, in UTC; false if the bytes are implausible
+func (db *DB) buildDate() (time.Time, bool) {
+	base := db.centuryBase
+	if base == 0 {
+		base = 2000
+	}
+	y := base + int(db.meta.databaseYear)
+	m := time.Month(db.meta.databaseMonth)
+	d := int(db.meta.databaseDay)
+	if db.meta.databaseYear > 99 || m < time.January || m > time.December || d < 1 {
+		return time.Time{}, false
+	}
+	t := time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	// time.Date normalizes overflowing days, e.g. February 30
+	if t.Month() != m {
+		return time.Time{}, false
+	}
+	return t, true
+}
@@ -0,0 +1,92 @@
+package ip2location
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// version of the encoding produced by Record.MarshalBinary
+const recordEncodingVersion = 1
+
+// MarshalBinary encodes the populated fields of x in a compact form: a version
+// byte, a uvarint Field mask of the fields that follow, then each field in the
+// order of the Field constants, strings as a uvarint length and their bytes,
+// floats as 4 little-endian bytes. Empty strings and zero floats are omitted,
+// so a negative zero decodes as a positive one.
+func (x *Record) MarshalBinary() ([]byte, error) {
+	var mask Field
+	size := 1 + binary.MaxVarintLen32
+	for _, e := range fieldNames {
+		str, f := x.fieldPtr(e.field)
+		if str != nil && *str != "" {
+			mask |= e.field
+			size += binary.MaxVarintLen32 + len(*str)
+		} else if f != nil && *f != 0 {
+			mask |= e.field
+			size += 4
+		}
+	}
+
+	b := make([]byte, 0, size)
+	b = append(b, recordEncodingVersion)
+	b = binary.AppendUvarint(b, uint64(mask))
+	for _, e := range fieldNames {
+		if mask&e.field == 0 {
+			continue
+		}
+		if str, f := x.fieldPtr(e.field); str != nil {
+			b = binary.AppendUvarint(b, uint64(len(*str)))
+			b = append(b, *str...)
+		} else {
+			b = binary.LittleEndian.AppendUint32(b, math.Float32bits(*f))
+		}
+	}
+	return b, nil
+}
+
+// UnmarshalBinary decodes data produced by MarshalBinary into x, replacing
+// its contents. On error x is left unchanged.
+func (x *Record) UnmarshalBinary(data []byte) error {
+	if len(data) == 0 {
+		return errors.New("ip2location: empty record encoding")
+	}
+	if data[0] != recordEncodingVersion {
+		return fmt.Errorf("ip2location: unsupported record encoding version %d", data[0])
+	}
+	data = data[1:]
+
+	m, n := binary.Uvarint(data)
+	if n <= 0 || Field(m)&^FieldAll != 0 {
+		return errors.New("ip2location: invalid record field mask")
+	}
+	mask := Field(m)
+	data = data[n:]
+
+	var y Record
+	for _, e := range fieldNames {
+		if mask&e.field == 0 {
+			continue
+		}
+		if str, f := y.fieldPtr(e.field); str != nil {
+			l, n := binary.Uvarint(data)
+			if n <= 0 || l > uint64(len(data)-n) {
+				return fmt.Errorf("ip2location: truncated record field %s", e.name)
+			}
+			*str = string(data[n : n+int(l)])
+			data = data[n+int(l):]
+		} else {
+			if len(data) < 4 {
+				return fmt.Errorf("ip2location: truncated record field %s", e.name)
+			}
+			*f = math.Float32frombits(binary.LittleEndian.Uint32(data))
+			data = data[4:]
+		}
+	}
+	if len(data) != 0 {
+		return errors.New("ip2location: trailing data after record")
+	}
+	*x = y
+	return nil
+}
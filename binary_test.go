@@ -0,0 +1,76 @@
+package ip2location
+
+import (
+	"math"
+	"testing"
+)
+
+func TestMarshalBinary(t *testing.T) {
+	// every field set to a distinct value
+	var full Record
+	for i, e := range fieldNames {
+		if str, f := full.fieldPtr(e.field); str != nil {
+			*str = e.label + " ü"
+		} else {
+			*f = float32(i) + 0.5
+		}
+	}
+	full.Latitude = -33.868820
+
+	for _, x := range []Record{
+		full,
+		{},
+		{CountryShort: "AU", CountryLong: "Australia"},
+		{Latitude: 37.405991, Longitude: -122.078514},
+	} {
+		b, err := x.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var y Record
+		if err := y.UnmarshalBinary(b); err != nil {
+			t.Errorf("%+v: %v", x, err)
+			continue
+		}
+		if y != x {
+			t.Errorf("round trip of %+v: got %+v", x, y)
+		}
+	}
+
+	// zero floats are dropped, negative ones included
+	b, err := (&Record{Latitude: float32(math.Copysign(0, -1))}).MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(b) != 2 {
+		t.Errorf("-0 latitude encoded as %x", b)
+	}
+}
+
+func TestUnmarshalBinaryErrors(t *testing.T) {
+	valid, err := (&Record{CountryShort: "AU", Latitude: 1}).MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range []struct {
+		name string
+		data []byte
+	}{
+		{"empty", nil},
+		{"version only", valid[:1]},
+		{"wrong version", append([]byte{recordEncodingVersion + 1}, valid[1:]...)},
+		{"truncated string", valid[:4]},
+		{"truncated float", valid[:len(valid)-1]},
+		{"unknown mask bits", []byte{recordEncodingVersion, 0x80, 0x80, 0x80, 0x80, 0x08}},
+		{"unterminated mask", []byte{recordEncodingVersion, 0x80}},
+		{"trailing bytes", append(valid[:len(valid):len(valid)], 0)},
+	} {
+		x := Record{City: "unchanged"}
+		if err := x.UnmarshalBinary(tt.data); err == nil {
+			t.Errorf("%s: no error", tt.name)
+		}
+		if x != (Record{City: "unchanged"}) {
+			t.Errorf("%s: record changed to %+v", tt.name, x)
+		}
+	}
+}
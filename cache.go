@@ -0,0 +1,59 @@
+package ip2location
+
+import (
+	"container/list"
+	"sync"
+)
+
+// strCache is a bounded LRU of decoded strings keyed by file offset.
+// It is safe for concurrent use.
+type strCache struct {
+	mu    sync.Mutex
+	size  int
+	ll    *list.List
+	items map[uint32]*list.Element
+}
+
+type strCacheEntry struct {
+	pos uint32
+	str string
+}
+
+func newStrCache(size int) *strCache {
+	return &strCache{
+		size:  size,
+		ll:    list.New(),
+		items: make(map[uint32]*list.Element, size),
+	}
+}
+
+// get returns the cached string at pos, if any
+func (c *strCache) get(pos uint32) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.items[pos]
+	if !ok {
+		return "", false
+	}
+	c.ll.MoveToFront(e)
+	return e.Value.(*strCacheEntry).str, true
+}
+
+// add stores str at pos, evicting the least recently used entry if full
+func (c *strCache) add(pos uint32, str string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if e, ok := c.items[pos]; ok {
+		c.ll.MoveToFront(e)
+		e.Value.(*strCacheEntry).str = str
+		return
+	}
+	c.items[pos] = c.ll.PushFront(&strCacheEntry{pos: pos, str: str})
+	if c.ll.Len() > c.size {
+		oldest := c.ll.Back()
+		c.ll.Remove(oldest)
+		delete(c.items, oldest.Value.(*strCacheEntry).pos)
+	}
+}
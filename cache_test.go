@@ -0,0 +1,48 @@
+package ip2location
+
+import "testing"
+
+// the addresses the lookup benchmarks cycle through
+var benchIPs = []string{"1.0.0.5", "1.0.1.200", "8.8.8.8", "9.9.9.9", "2001:4860:4860::8888", "2400:cb00::1"}
+
+func BenchmarkGetAllCached(b *testing.B) {
+	db := testDB5.openFile(b, WithStringCache(64))
+	benchmarkGetAll(b, db)
+}
+
+func BenchmarkGetAllUncached(b *testing.B) {
+	db := testDB5.openFile(b)
+	benchmarkGetAll(b, db)
+}
+
+func benchmarkGetAll(b *testing.B, db *DB) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := db.GetAll(benchIPs[i%len(benchIPs)]); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestStringCache(t *testing.T) {
+	db := testDB5.openFile(t, WithStringCache(2))
+	plain := testDB5.openFile(t)
+	for range 3 {
+		for _, ip := range benchIPs {
+			x, err := db.GetAll(ip)
+			if err != nil {
+				t.Fatal(err)
+			}
+			y, err := plain.GetAll(ip)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if *x != *y {
+				t.Errorf("%s: cached %+v, uncached %+v", ip, x, y)
+			}
+		}
+	}
+	if n := db.strCache.ll.Len(); n > 2 {
+		t.Errorf("cache holds %d strings, want at most 2", n)
+	}
+}
@@ -0,0 +1,88 @@
+package ip2location
+
+import (
+	"errors"
+	"io"
+	"sync"
+	"sync/atomic"
+)
+
+// refCloser closes c once every handle sharing it has released it
+type refCloser struct {
+	mu      sync.Mutex
+	c       io.Closer
+	refs    int
+	release func() // called after the last release, if set
+}
+
+// add a reference; false if c is already closed
+func (r *refCloser) acquire() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.refs == 0 {
+		return false
+	}
+	r.refs++
+	return true
+}
+
+func (r *refCloser) Close() error {
+	r.mu.Lock()
+	if r.refs == 0 {
+		r.mu.Unlock()
+		return nil
+	}
+	r.refs--
+	last := r.refs == 0
+	r.mu.Unlock()
+
+	if !last {
+		return nil
+	}
+	// release may take other locks, so call it without holding r.mu
+	if r.release != nil {
+		r.release()
+	}
+	return r.c.Close()
+}
+
+var errClonedClosed = errors.New("ip2location: clone of a closed database")
+
+type nopCloser struct{}
+
+func (nopCloser) Close() error { return nil }
+
+// Clone returns a new handle on the same backing file, mapping or memory,
+// without reopening it. The clone has its own string cache, empty at first,
+// and its own counters if WithStats is enabled; a cached index is shared
+// since it never changes. The backing storage is
+// reference counted: it is released when the original and all its clones
+// have been closed, in any order. Cloning a closed handle returns an error.
+func (db *DB) Clone() (*DB, error) {
+	if atomic.LoadUint32(db.closed) != 0 {
+		return nil, errClonedClosed
+	}
+	return db.clone()
+}
+
+// clone db even if it was closed, as long as a clone still holds the
+// backing storage
+func (db *DB) clone() (*DB, error) {
+	if rc, ok := db.closer.(*refCloser); ok && !rc.acquire() {
+		return nil, errClonedClosed
+	}
+
+	c := &DB{}
+	*c = *db
+	c.closed = new(uint32)
+	if db.strCache != nil {
+		c.strCache = newStrCache(db.strCache.size)
+	}
+	if db.memo != nil {
+		c.memo = &rowMemo{}
+	}
+	if db.stats != nil {
+		c.stats = &stats{}
+	}
+	return c, nil
+}
@@ -0,0 +1,189 @@
+package ip2location
+
+import "testing"
+
+// the reference count of db's backing file
+func refs(t *testing.T, db *DB) int {
+	t.Helper()
+	rc, ok := db.closer.(*refCloser)
+	if !ok {
+		t.Fatalf("closer is %T, want *refCloser", db.closer)
+	}
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	return rc.refs
+}
+
+func TestCloseTwice(t *testing.T) {
+	db, err := Open(testDB5.write(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Close(); err != nil {
+		t.Fatalf("first Close: %v", err)
+	}
+	if err := db.Close(); err != nil {
+		t.Errorf("second Close: %v", err)
+	}
+	if refs(t, db) != 0 {
+		t.Errorf("refs = %d after Close, want 0", refs(t, db))
+	}
+	if _, err := db.GetAll("8.8.8.8"); err == nil {
+		t.Error("GetAll on a closed database succeeded")
+	}
+
+	var nilDB *DB
+	if err := nilDB.Close(); err != nil {
+		t.Errorf("Close on nil: %v", err)
+	}
+}
+
+func TestCloseClone(t *testing.T) {
+	db, err := Open(testDB5.write(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := db.Clone()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if refs(t, db) != 2 {
+		t.Fatalf("refs = %d after Clone, want 2", refs(t, db))
+	}
+
+	// closing the original twice releases one reference only
+	for range 2 {
+		if err := db.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if refs(t, c) != 1 {
+		t.Errorf("refs = %d after closing the original twice, want 1", refs(t, c))
+	}
+	if x, err := c.GetAll("8.8.8.8"); err != nil || x.CountryShort != "US" {
+		t.Errorf("clone after closing the original: %v %+v", err, x)
+	}
+
+	for range 2 {
+		if err := c.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if refs(t, c) != 0 {
+		t.Errorf("refs = %d after closing both, want 0", refs(t, c))
+	}
+	if _, err := c.Clone(); err == nil {
+		t.Error("Clone of a closed database succeeded")
+	}
+}
+
+func TestCloseShared(t *testing.T) {
+	path := testDB5.write(t)
+	a, err := Open(path, WithShared())
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := Open(path, WithShared())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if refs(t, a) != 2 {
+		t.Fatalf("refs = %d for two shared opens, want 2", refs(t, a))
+	}
+
+	for range 2 {
+		if err := a.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if refs(t, b) != 1 {
+		t.Errorf("refs = %d after closing one handle twice, want 1", refs(t, b))
+	}
+	if x, err := b.GetAll("8.8.8.8"); err != nil || x.CountryShort != "US" {
+		t.Errorf("remaining handle: %v %+v", err, x)
+	}
+
+	if err := b.Close(); err != nil {
+		t.Fatal(err)
+	}
+	shared.mu.Lock()
+	n := len(shared.dbs)
+	shared.mu.Unlock()
+	if n != 0 {
+		t.Errorf("%d shared handles left after closing all", n)
+	}
+	if err := b.Close(); err != nil {
+		t.Errorf("second Close: %v", err)
+	}
+}
+
+func TestCloneClosed(t *testing.T) {
+	db, err := Open(testDB5.write(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := db.Clone()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	db.Close()
+	if _, err := db.Clone(); err == nil {
+		t.Error("Clone of a closed handle with a live clone succeeded")
+	}
+	if _, err := c.Clone(); err != nil {
+		t.Errorf("Clone of the live clone: %v", err)
+	}
+
+	m, err := OpenBytes(testDB5.build())
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Close()
+	if _, err := m.Clone(); err == nil {
+		t.Error("Clone of a closed in-memory handle succeeded")
+	}
+}
+
+func TestCloneWhileClosing(t *testing.T) {
+	db, err := Open(testDB5.write(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for range 100 {
+			if c, err := db.Clone(); err == nil {
+				c.Close()
+			}
+		}
+	}()
+	db.Close()
+	<-done
+}
+
+// closing the first of several shared handles does not stop later opens
+// from sharing the backing file
+func TestSharedAfterFirstClosed(t *testing.T) {
+	path := testDB5.write(t)
+	a, err := Open(path, WithShared())
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := Open(path, WithShared())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer b.Close()
+	a.Close()
+
+	c, err := Open(path, WithShared())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	if refs(t, c) != 2 || c.closer != b.closer {
+		t.Errorf("refs = %d, want 2 on the same backing file", refs(t, c))
+	}
+}
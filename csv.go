@@ -0,0 +1,28 @@
+package ip2location
+
+import "strconv"
+
+// CSVHeader returns the column names matching Record.CSVRecord: the labels
+// of Record.String, in the order of the Field constants.
+func CSVHeader() []string {
+	h := make([]string, len(fieldNames))
+	for i, e := range fieldNames {
+		h[i] = e.label
+	}
+	return h
+}
+
+// CSVRecord returns the fields of x in the column order of CSVHeader, for use
+// with encoding/csv. Floats are formatted with six decimals; empty strings
+// and zero floats give empty columns.
+func (x *Record) CSVRecord() []string {
+	r := make([]string, len(fieldNames))
+	for i, e := range fieldNames {
+		if str, f := x.fieldPtr(e.field); str != nil {
+			r[i] = *str
+		} else if *f != 0 {
+			r[i] = strconv.FormatFloat(float64(*f), 'f', 6, 32)
+		}
+	}
+	return r
+}
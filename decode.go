@@ -0,0 +1,13 @@
+package ip2location
+
+import "unicode/utf8"
+
+// DecodeLatin1 converts ISO 8859-1 bytes to a UTF-8 string, for use with
+// WithStringDecoder.
+func DecodeLatin1(b []byte) string {
+	buf := make([]byte, 0, len(b)*2)
+	for _, c := range b {
+		buf = utf8.AppendRune(buf, rune(c))
+	}
+	return string(buf)
+}
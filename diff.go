@@ -0,0 +1,81 @@
+package ip2location
+
+import (
+	"net/netip"
+	"sort"
+)
+
+// DiffStat summarizes the differences between two databases, by range.
+type DiffStat struct {
+	OldRanges        int      // ranges in the old database
+	NewRanges        int      // ranges in the new database
+	Added            int      // ranges only in the new database
+	Removed          int      // ranges only in the old database
+	ChangedCountry   int      // ranges in both whose country code differs
+	CountriesAdded   []string // country codes only in the new database
+	CountriesRemoved []string // country codes only in the old database
+}
+
+// Diff compares the databases at oldPath and newPath range by range. A range
+// split or merged between versions counts as removed and added. It reads both
+// databases in full and holds the old ranges in memory, so it is meant for
+// offline validation of updates, not for the request path.
+func Diff(oldPath, newPath string) (DiffStat, error) {
+	var st DiffStat
+
+	type bounds struct{ from, to netip.Addr }
+	old := make(map[bounds]string)
+	oldCountries := make(map[string]bool)
+	if err := iterateFile(oldPath, func(from, to netip.Addr, rec *Record) bool {
+		old[bounds{from, to}] = rec.CountryShort
+		oldCountries[rec.CountryShort] = true
+		return true
+	}); err != nil {
+		return DiffStat{}, err
+	}
+	st.OldRanges = len(old)
+
+	newCountries := make(map[string]bool)
+	if err := iterateFile(newPath, func(from, to netip.Addr, rec *Record) bool {
+		st.NewRanges++
+		newCountries[rec.CountryShort] = true
+		country, ok := old[bounds{from, to}]
+		switch {
+		case !ok:
+			st.Added++
+		case country != rec.CountryShort:
+			st.ChangedCountry++
+		}
+		if ok {
+			delete(old, bounds{from, to})
+		}
+		return true
+	}); err != nil {
+		return DiffStat{}, err
+	}
+	st.Removed = len(old)
+
+	for c := range newCountries {
+		if !oldCountries[c] {
+			st.CountriesAdded = append(st.CountriesAdded, c)
+		}
+	}
+	for c := range oldCountries {
+		if !newCountries[c] {
+			st.CountriesRemoved = append(st.CountriesRemoved, c)
+		}
+	}
+	sort.Strings(st.CountriesAdded)
+	sort.Strings(st.CountriesRemoved)
+	return st, nil
+}
+
+// open the database at path and iterate over its ranges
+func iterateFile(path string, fn func(from, to netip.Addr, rec *Record) bool) error {
+	db, err := Open(path)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	return db.Iterate(fn)
+}
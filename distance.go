@@ -0,0 +1,43 @@
+package ip2location
+
+import (
+	"fmt"
+	"math"
+)
+
+// mean Earth radius in kilometers
+const earthRadiusKm = 6371.0088
+
+// Distance returns the great-circle distance in kilometers between the
+// locations of two IP addresses. If either address has no coordinates,
+// including the 0,0 placeholder, the error wraps ErrNoCoordinates so callers
+// can skip the check rather than treat it as zero distance.
+func (db *DB) Distance(ipA, ipB string) (km float64, err error) {
+	a, err := db.query(ipA, FieldLatitude|FieldLongitude)
+	if err != nil {
+		return 0, err
+	}
+	pa, ok := a.Point()
+	if !ok {
+		return 0, fmt.Errorf("%s: %w", ipA, ErrNoCoordinates)
+	}
+	b, err := db.query(ipB, FieldLatitude|FieldLongitude)
+	if err != nil {
+		return 0, err
+	}
+	pb, ok := b.Point()
+	if !ok {
+		return 0, fmt.Errorf("%s: %w", ipB, ErrNoCoordinates)
+	}
+	return pa.DistanceKm(pb), nil
+}
+
+// great-circle distance in kilometers between two points given in degrees
+func haversine(lat1, lon1, lat2, lon2 float64) float64 {
+	const rad = math.Pi / 180
+	dlat := (lat2 - lat1) * rad
+	dlon := (lon2 - lon1) * rad
+	a := math.Sin(dlat/2)*math.Sin(dlat/2) +
+		math.Cos(lat1*rad)*math.Cos(lat2*rad)*math.Sin(dlon/2)*math.Sin(dlon/2)
+	return 2 * earthRadiusKm * math.Asin(math.Min(1, math.Sqrt(a)))
+}
@@ -0,0 +1,97 @@
+package ip2location
+
+import "net/netip"
+
+// ExportRow is one database range in a flat form suited to columnar formats
+// such as Parquet or Arrow. The struct tags give the column names, which are
+// those of ExportSchema.
+type ExportRow struct {
+	IPFrom             string  `parquet:"ip_from" json:"ip_from"`
+	IPTo               string  `parquet:"ip_to" json:"ip_to"`
+	CountryShort       string  `parquet:"country_short" json:"country_short"`
+	CountryLong        string  `parquet:"country_long" json:"country_long"`
+	Region             string  `parquet:"region" json:"region"`
+	City               string  `parquet:"city" json:"city"`
+	Isp                string  `parquet:"isp" json:"isp"`
+	Latitude           float32 `parquet:"latitude" json:"latitude"`
+	Longitude          float32 `parquet:"longitude" json:"longitude"`
+	Domain             string  `parquet:"domain" json:"domain"`
+	Zipcode            string  `parquet:"zipcode" json:"zipcode"`
+	TimeZone           string  `parquet:"timezone" json:"timezone"`
+	NetSpeed           string  `parquet:"netspeed" json:"netspeed"`
+	IddCode            string  `parquet:"iddcode" json:"iddcode"`
+	Areacode           string  `parquet:"areacode" json:"areacode"`
+	WeatherStationCode string  `parquet:"weatherstationcode" json:"weatherstationcode"`
+	WeatherStationName string  `parquet:"weatherstationname" json:"weatherstationname"`
+	Mcc                string  `parquet:"mcc" json:"mcc"`
+	Mnc                string  `parquet:"mnc" json:"mnc"`
+	MobileBrand        string  `parquet:"mobilebrand" json:"mobilebrand"`
+	Elevation          float32 `parquet:"elevation" json:"elevation"`
+	UsageType          string  `parquet:"usagetype" json:"usagetype"`
+	ProxyType          string  `parquet:"proxytype" json:"proxytype"`
+	Threat             string  `parquet:"threat" json:"threat"`
+	Provider           string  `parquet:"provider" json:"provider"`
+}
+
+// ExportColumn describes a column of an export.
+type ExportColumn struct {
+	Name string // as in the ExportRow struct tags
+	Type string // "string" or "float32"
+}
+
+// ExportSchema returns the columns an export of this database fills, in the
+// order of ExportRow: ip_from and ip_to, then the fields the database
+// carries, named after the labels of Record.String. Columns of ExportRow not
+// listed are always empty and may be left out of the written schema.
+func (db *DB) ExportSchema() []ExportColumn {
+	cols := []ExportColumn{{"ip_from", "string"}, {"ip_to", "string"}}
+	fields := db.Fields()
+	for _, e := range fieldNames {
+		if fields&e.field == 0 {
+			continue
+		}
+		if str, _ := (&Record{}).fieldPtr(e.field); str != nil {
+			cols = append(cols, ExportColumn{e.label, "string"})
+		} else {
+			cols = append(cols, ExportColumn{e.label, "float32"})
+		}
+	}
+	return cols
+}
+
+// Export calls fn for every range in the database, in the order of Iterate,
+// with the range as an ExportRow. The row is reused between calls, so fn must
+// copy it to keep it. Returning false from fn stops the export.
+func (db *DB) Export(fn func(row *ExportRow) bool) error {
+	row := &ExportRow{}
+	return db.Iterate(func(from, to netip.Addr, x *Record) bool {
+		*row = ExportRow{
+			IPFrom:             from.String(),
+			IPTo:               to.String(),
+			CountryShort:       x.CountryShort,
+			CountryLong:        x.CountryLong,
+			Region:             x.Region,
+			City:               x.City,
+			Isp:                x.Isp,
+			Latitude:           x.Latitude,
+			Longitude:          x.Longitude,
+			Domain:             x.Domain,
+			Zipcode:            x.Zipcode,
+			TimeZone:           x.TimeZone,
+			NetSpeed:           x.NetSpeed,
+			IddCode:            x.IddCode,
+			Areacode:           x.Areacode,
+			WeatherStationCode: x.WeatherStationCode,
+			WeatherStationName: x.WeatherStationName,
+			Mcc:                x.Mcc,
+			Mnc:                x.Mnc,
+			MobileBrand:        x.MobileBrand,
+			Elevation:          x.Elevation,
+			UsageType:          x.UsageType,
+			ProxyType:          x.ProxyType,
+			Threat:             x.Threat,
+			Provider:           x.Provider,
+		}
+		return fn(row)
+	})
+}
@@ -0,0 +1,70 @@
+package ip2location
+
+// GetAllOrDefault gets all fields for the IP address, substituting def where
+// the database has no answer: on a miss a copy of def is returned, and on a
+// hit the fields the database does not carry are copied from def. Malformed
+// addresses and read errors are still returned as errors. A nil def behaves
+// like an empty Record.
+func (db *DB) GetAllOrDefault(ipaddress string, def *Record) (*Record, error) {
+	iptype, ipno, ipindex := db.checkIP(ipaddress)
+	if iptype == 0 {
+		return nil, ErrInvalidAddress
+	}
+
+	x := &Record{}
+	rowoffset, found, err := db.find(iptype, ipno, ipindex)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		if def != nil {
+			*x = *def
+		}
+		return x, nil
+	}
+	if err := db.readRecordInto(x, iptype, rowoffset, FieldAll); err != nil {
+		return nil, err
+	}
+	if def == nil {
+		return x, nil
+	}
+
+	missing := FieldAll &^ db.Fields()
+	for _, e := range fieldNames {
+		if missing&e.field == 0 {
+			continue
+		}
+		str, f := x.fieldPtr(e.field)
+		defStr, defF := def.fieldPtr(e.field)
+		if str != nil {
+			*str = *defStr
+		} else {
+			*f = *defF
+		}
+	}
+	return x, nil
+}
+
+// GetAllNearest is like GetAll, but when the address falls in a gap between
+// ranges it returns the Record of the closest preceding range and reports
+// approximate as true. If no range precedes the address, the Record is empty
+// and approximate is false. It is meant for investigating gaps in a database.
+func (db *DB) GetAllNearest(ipaddress string) (x *Record, approximate bool, err error) {
+	iptype, ipno, ipindex := db.checkIP(ipaddress)
+	if iptype == 0 {
+		return nil, false, ErrInvalidAddress
+	}
+
+	rowoffset, found, err := db.find(iptype, ipno, ipindex)
+	if err != nil {
+		return nil, false, err
+	}
+	if rowoffset == 0 {
+		return &Record{}, false, nil
+	}
+	x, err = db.readRecord(iptype, rowoffset, FieldAll)
+	if err != nil {
+		return nil, false, err
+	}
+	return x, !found, nil
+}
@@ -0,0 +1,50 @@
+package ip2location
+
+import (
+	"encoding/binary"
+	"testing"
+)
+
+func TestGetAllNearest(t *testing.T) {
+	// the index entry of 8.8.0.0/16 points past the 8.8.8.0 row, so that
+	// the search finds 8.8.8.8 in a gap after it
+	gap := testDB{Type: 5, V4: testRangesV4, Index: true}.build()
+	idx := binary.LittleEndian.Uint32(gap[21:]) - 1
+	binary.LittleEndian.PutUint32(gap[idx+0x0808*8:], 4)
+	binary.LittleEndian.PutUint32(gap[idx+0x0808*8+4:], 4)
+	gapDB, err := OpenBytes(gap)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer gapDB.Close()
+
+	for _, tt := range []struct {
+		name        string
+		db          *DB
+		ip          string
+		country     string
+		approximate bool
+	}{
+		{"exact", testDB5.open(t), "8.8.8.8", "US", false},
+		{"exact IPv6", testDB5.open(t), "2400:cb00::1", "AU", false},
+		{"gap", gapDB, "8.8.8.8", "US", true},
+		{"before the first range", testDB{Type: 5, V4: testRangesV4[1:]}.open(t), "0.1.2.3", "", false},
+	} {
+		x, approximate, err := tt.db.GetAllNearest(tt.ip)
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if x.CountryShort != tt.country || approximate != tt.approximate {
+			t.Errorf("%s: GetAllNearest(%s) = %q, %v, want %q, %v", tt.name, tt.ip, x.CountryShort, approximate, tt.country, tt.approximate)
+		}
+	}
+
+	// the gap is a miss for GetAll
+	if _, found, err := gapDB.GetAllFound("8.8.8.8"); err != nil || found {
+		t.Errorf("GetAllFound in the gap = %v, %v, want a miss", found, err)
+	}
+	if _, _, err := gapDB.GetAllNearest("8.8.8"); err != ErrInvalidAddress {
+		t.Errorf("malformed address: err = %v, want ErrInvalidAddress", err)
+	}
+}
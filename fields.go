@@ -0,0 +1,140 @@
+package ip2location
+
+// fieldNames maps each Field to its canonical name, the name of the
+// corresponding Record field, and to its label in Record.String.
+var fieldNames = [...]struct {
+	field Field
+	name  string
+	label string
+}{
+	{FieldCountryShort, "CountryShort", "country_short"},
+	{FieldCountryLong, "CountryLong", "country_long"},
+	{FieldRegion, "Region", "region"},
+	{FieldCity, "City", "city"},
+	{FieldISP, "Isp", "isp"},
+	{FieldLatitude, "Latitude", "latitude"},
+	{FieldLongitude, "Longitude", "longitude"},
+	{FieldDomain, "Domain", "domain"},
+	{FieldZipCode, "Zipcode", "zipcode"},
+	{FieldTimeZone, "TimeZone", "timezone"},
+	{FieldNetSpeed, "NetSpeed", "netspeed"},
+	{FieldIDDCode, "IddCode", "iddcode"},
+	{FieldAreaCode, "Areacode", "areacode"},
+	{FieldWeatherStationCode, "WeatherStationCode", "weatherstationcode"},
+	{FieldWeatherStationName, "WeatherStationName", "weatherstationname"},
+	{FieldMCC, "Mcc", "mcc"},
+	{FieldMNC, "Mnc", "mnc"},
+	{FieldMobileBrand, "MobileBrand", "mobilebrand"},
+	{FieldElevation, "Elevation", "elevation"},
+	{FieldUsageType, "UsageType", "usagetype"},
+	{FieldProxyType, "ProxyType", "proxytype"},
+	{FieldThreat, "Threat", "threat"},
+	{FieldProvider, "Provider", "provider"},
+}
+
+// Columns returns the number of columns per row, as stored in the header.
+func (db *DB) Columns() int {
+	return int(db.meta.databesColumn)
+}
+
+// Fields returns the mask of fields the database carries.
+func (db *DB) Fields() Field {
+	var f Field
+	for _, e := range []struct {
+		field   Field
+		enabled bool
+	}{
+		{FieldCountryShort | FieldCountryLong, db.countryEnabled},
+		{FieldRegion, db.regionEnabled},
+		{FieldCity, db.cityEnabled},
+		{FieldISP, db.ispEnabled},
+		{FieldLatitude, db.latitudeEnabled},
+		{FieldLongitude, db.longitudeEnabled},
+		{FieldDomain, db.domainEnabled},
+		{FieldZipCode, db.zipCodeEnabled},
+		{FieldTimeZone, db.timeZoneEnabled},
+		{FieldNetSpeed, db.netSpeedEnabled},
+		{FieldIDDCode, db.iddCodeEnabled},
+		{FieldAreaCode, db.areaCodeEnabled},
+		{FieldWeatherStationCode, db.weatherStationCodeEnabled},
+		{FieldWeatherStationName, db.weatherStationNameEnabled},
+		{FieldMCC, db.mccEnabled},
+		{FieldMNC, db.mncEnabled},
+		{FieldMobileBrand, db.mobileBrandEnabled},
+		{FieldElevation, db.elevationEnabled},
+		{FieldUsageType, db.usageTypeEnabled},
+		{FieldProxyType, db.proxyTypeEnabled},
+		{FieldThreat, db.threatEnabled},
+		{FieldProvider, db.providerEnabled},
+	} {
+		if e.enabled {
+			f |= e.field
+		}
+	}
+	return f
+}
+
+// FieldMap reports, for every field keyed by its canonical name, whether the
+// database carries it.
+func (db *DB) FieldMap() map[string]bool {
+	f := db.Fields()
+	m := make(map[string]bool, len(fieldNames))
+	for _, e := range fieldNames {
+		m[e.name] = f&e.field != 0
+	}
+	return m
+}
+
+// return a pointer to the field of x selected by f: a string for text fields,
+// a float32 for latitude, longitude and elevation
+func (x *Record) fieldPtr(f Field) (*string, *float32) {
+	switch f {
+	case FieldCountryShort:
+		return &x.CountryShort, nil
+	case FieldCountryLong:
+		return &x.CountryLong, nil
+	case FieldRegion:
+		return &x.Region, nil
+	case FieldCity:
+		return &x.City, nil
+	case FieldISP:
+		return &x.Isp, nil
+	case FieldLatitude:
+		return nil, &x.Latitude
+	case FieldLongitude:
+		return nil, &x.Longitude
+	case FieldDomain:
+		return &x.Domain, nil
+	case FieldZipCode:
+		return &x.Zipcode, nil
+	case FieldTimeZone:
+		return &x.TimeZone, nil
+	case FieldNetSpeed:
+		return &x.NetSpeed, nil
+	case FieldIDDCode:
+		return &x.IddCode, nil
+	case FieldAreaCode:
+		return &x.Areacode, nil
+	case FieldWeatherStationCode:
+		return &x.WeatherStationCode, nil
+	case FieldWeatherStationName:
+		return &x.WeatherStationName, nil
+	case FieldMCC:
+		return &x.Mcc, nil
+	case FieldMNC:
+		return &x.Mnc, nil
+	case FieldMobileBrand:
+		return &x.MobileBrand, nil
+	case FieldElevation:
+		return nil, &x.Elevation
+	case FieldUsageType:
+		return &x.UsageType, nil
+	case FieldProxyType:
+		return &x.ProxyType, nil
+	case FieldThreat:
+		return &x.Threat, nil
+	case FieldProvider:
+		return &x.Provider, nil
+	}
+	return nil, nil
+}
@@ -0,0 +1,34 @@
+package ip2location
+
+import (
+	"io"
+	"io/fs"
+)
+
+// OpenFS opens the named database file from fsys, such as an embed.FS or a
+// zip.Reader. If the file supports io.ReaderAt it is read from directly,
+// otherwise its contents are loaded into memory. WithMmap has no effect here.
+func OpenFS(fsys fs.FS, name string, opts ...Option) (*DB, error) {
+	o := newOptions(opts)
+
+	f, err := fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+
+	if ra, ok := f.(io.ReaderAt); ok {
+		db, err := newDB(ra, f, o)
+		if err != nil {
+			f.Close()
+			return nil, err
+		}
+		return db, nil
+	}
+
+	data, err := io.ReadAll(f)
+	f.Close()
+	if err != nil {
+		return nil, err
+	}
+	return newDB(&bytesReader{data: data}, nil, o)
+}
@@ -0,0 +1,53 @@
+package ip2location
+
+import (
+	"net/netip"
+	"testing"
+)
+
+// lookups on corrupt databases must fail with an error, never panic or hang
+func FuzzQuery(f *testing.F) {
+	full := testDB{Type: 24, V4: testRangesV4[:3], V6: testRangesV6[:2]}.build()
+	for _, data := range [][]byte{
+		testDB5.build(),
+		full,
+		full[:29],
+		full[:64],
+		full[:len(full)/2],
+		testDB{Type: 5, Columns: 2, V4: testRangesV4}.build(),
+		testDB{Type: 3, Product: productIP2Proxy, V4: testRangesV4}.build(),
+	} {
+		f.Add(data)
+	}
+	// headers with a mutated type, column count and table counts
+	for _, mutate := range []func(b []byte){
+		func(b []byte) { b[0] = 0xff },
+		func(b []byte) { b[1] = 0 },
+		func(b []byte) { b[1] = 0xff },
+		func(b []byte) { b[5], b[6], b[7], b[8] = 0xff, 0xff, 0xff, 0x7f },
+		func(b []byte) { b[9] = 0 },
+		func(b []byte) { b[17], b[18], b[19], b[20] = 0xf0, 0xff, 0xff, 0xff },
+	} {
+		b := append([]byte(nil), full...)
+		mutate(b)
+		f.Add(b)
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		db, err := OpenBytes(data)
+		if err != nil {
+			return
+		}
+		defer db.Close()
+		for _, ip := range []string{"1.0.0.1", "0.0.0.0", "255.255.255.255", "::1", "2001:4860::1", "::ffff:1.2.3.4",
+			"ffff:ffff:ffff:ffff:ffff:ffff:ffff:ffff"} {
+			db.GetAll(ip)
+			db.Query(ip, FieldCountryShort|FieldCity|FieldElevation)
+			db.GetAllPartial(ip)
+			db.GetAllRange(ip)
+		}
+		db.Metadata()
+		n := 0
+		db.Iterate(func(_, _ netip.Addr, _ *Record) bool { n++; return n < 50 })
+	})
+}
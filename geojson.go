@@ -0,0 +1,34 @@
+package ip2location
+
+import "encoding/json"
+
+type geoJSONFeature struct {
+	Type       string            `json:"type"`
+	Geometry   *geoJSONPoint     `json:"geometry"`
+	Properties map[string]string `json:"properties"`
+}
+
+type geoJSONPoint struct {
+	Type        string     `json:"type"`
+	Coordinates [2]float32 `json:"coordinates"`
+}
+
+// GeoJSON encodes x as a GeoJSON Feature with a Point geometry at its
+// coordinates and the location fields as properties. Coordinates of exactly
+// 0,0 are the databases' placeholder for "unknown" and give a null geometry.
+func (x *Record) GeoJSON() ([]byte, error) {
+	f := geoJSONFeature{
+		Type: "Feature",
+		Properties: map[string]string{
+			"country_short": x.CountryShort,
+			"country_long":  x.CountryLong,
+			"region":        x.Region,
+			"city":          x.City,
+		},
+	}
+	if x.HasCoordinates() {
+		// GeoJSON positions are longitude first
+		f.Geometry = &geoJSONPoint{Type: "Point", Coordinates: [2]float32{x.Longitude, x.Latitude}}
+	}
+	return json.Marshal(f)
+}
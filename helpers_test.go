@@ -0,0 +1,293 @@
+package ip2location
+
+import (
+	"encoding/binary"
+	"math"
+	"math/big"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+// testRange is one row of a synthetic database: addresses from From up to
+// the next range's From resolve to Rec.
+type testRange struct {
+	From string
+	Rec  Record
+
+	// raw elevation string, for rows the Record cannot express; formatted
+	// from Rec.Elevation when empty
+	Elevation string
+}
+
+// testDB describes a synthetic BIN file. Only the tables with ranges are
+// written; each gets the two sentinel rows real databases end with.
+type testDB struct {
+	Type    uint8
+	Columns uint8 // header column count, from the type when zero
+	Product uint8
+	V4, V6  []testRange
+	Index   bool             // write the index tables
+	Order   binary.ByteOrder // little-endian when nil
+}
+
+// the layout of a few small databases used throughout the tests
+var (
+	testRangesV4 = []testRange{
+		{From: "0.0.0.0", Rec: Record{CountryShort: "-", CountryLong: "-"}},
+		{From: "1.0.0.0", Rec: Record{CountryShort: "AU", CountryLong: "Australia", City: "Brisbane", Latitude: -27.467939, Longitude: 153.028091}},
+		{From: "1.0.1.0", Rec: Record{CountryShort: "CN", CountryLong: "China", City: "Fuzhou", Latitude: 26.061390, Longitude: 119.306107}},
+		{From: "8.8.8.0", Rec: Record{CountryShort: "US", CountryLong: "United States of America", City: "Mountain View", Latitude: 37.405991, Longitude: -122.078514}},
+		{From: "8.8.9.0", Rec: Record{CountryShort: "-", CountryLong: "-"}},
+	}
+	testRangesV6 = []testRange{
+		{From: "::", Rec: Record{CountryShort: "-", CountryLong: "-"}},
+		{From: "2001:4860::", Rec: Record{CountryShort: "US", CountryLong: "United States of America", City: "Mountain View", Latitude: 37.405991, Longitude: -122.078514}},
+		{From: "2001:4861::", Rec: Record{CountryShort: "-", CountryLong: "-"}},
+		{From: "2400:cb00::", Rec: Record{CountryShort: "AU", CountryLong: "Australia", City: "Sydney", Latitude: -33.868820, Longitude: 151.209290}},
+		{From: "2400:cb01::", Rec: Record{CountryShort: "-", CountryLong: "-"}},
+	}
+)
+
+// build returns the BIN file described by t.
+func (t testDB) build() []byte {
+	probe := &DB{meta: &dbMeta{}}
+	cols := typeColumns(t.Type)
+	if t.Product == productIP2Proxy {
+		probe.setProxyColumns(t.Type)
+		cols = proxyColumns[t.Type]
+	} else {
+		probe.setColumns(t.Type)
+	}
+	if t.Columns != 0 {
+		cols = t.Columns
+	}
+	order := t.Order
+	if order == nil {
+		order = binary.LittleEndian
+	}
+	size4 := uint32(cols) * 4
+	size6 := 16 + uint32(cols-1)*4
+
+	hdr := make([]byte, 64)
+	hdr[0], hdr[1], hdr[2], hdr[3], hdr[4] = t.Type, cols, 24, 5, 1
+	hdr[29] = t.Product
+
+	// lay out the index tables, then the rows, then the strings
+	off := uint32(len(hdr))
+	var idx4, idx6, rows4, rows6 uint32
+	if t.Index && len(t.V4) > 0 {
+		idx4, off = off, off+indexSize
+	}
+	if t.Index && len(t.V6) > 0 {
+		idx6, off = off, off+indexSize
+	}
+	if len(t.V4) > 0 {
+		rows4, off = off, off+uint32(len(t.V4)+2)*size4
+	}
+	if len(t.V6) > 0 {
+		rows6, off = off, off+uint32(len(t.V6)+2)*size6
+	}
+	order.PutUint32(hdr[5:], uint32(len(t.V4)))
+	order.PutUint32(hdr[9:], rows4+1)
+	order.PutUint32(hdr[13:], uint32(len(t.V6)))
+	order.PutUint32(hdr[17:], rows6+1)
+	if idx4 != 0 {
+		order.PutUint32(hdr[21:], idx4+1)
+	}
+	if idx6 != 0 {
+		order.PutUint32(hdr[25:], idx6+1)
+	}
+	if len(t.V4) == 0 {
+		order.PutUint32(hdr[9:], 0)
+	}
+	if len(t.V6) == 0 {
+		order.PutUint32(hdr[17:], 0)
+	}
+
+	b := make([]byte, off, off+4096)
+	copy(b, hdr)
+
+	strs := map[string]uint32{}
+	str := func(s string) uint32 {
+		if p, ok := strs[s]; ok {
+			return p
+		}
+		p := uint32(len(b))
+		b = append(b, byte(len(s)))
+		b = append(b, s...)
+		strs[s] = p
+		return p
+	}
+	// the short name is padded so that the long name is 3 bytes on
+	country := func(short, long string) uint32 {
+		p := uint32(len(b))
+		b = append(b, byte(len(short)))
+		b = append(b, (short + "  ")[:2]...)
+		b = append(b, byte(len(long)))
+		b = append(b, long...)
+		return p
+	}
+
+	// the columns after the first, 4 bytes each
+	columns := func(r testRange) []byte {
+		c := make([]byte, size4-4)
+		put := func(off, v uint32) {
+			if off >= 4 && off+4 <= size4 {
+				order.PutUint32(c[off-4:], v)
+			}
+		}
+		x := r.Rec
+		if probe.countryEnabled {
+			put(probe.countryPositionOffset, country(x.CountryShort, x.CountryLong))
+		}
+		if probe.latitudeEnabled {
+			put(probe.latitudePositionOffset, math.Float32bits(x.Latitude))
+		}
+		if probe.longitudeEnabled {
+			put(probe.longitudePositionOffset, math.Float32bits(x.Longitude))
+		}
+		for _, e := range fieldNames {
+			off, ok := probe.pointerColumn(e.field)
+			if !ok || e.field&(FieldCountryShort|FieldCountryLong) != 0 {
+				continue
+			}
+			if e.field == FieldElevation {
+				s := r.Elevation
+				if s == "" {
+					s = strconv.FormatFloat(float64(x.Elevation), 'f', -1, 32)
+				}
+				put(off, str(s))
+				continue
+			}
+			s, _ := x.fieldPtr(e.field)
+			put(off, str(*s))
+		}
+		return c
+	}
+
+	// two sentinel rows follow the last range, as in real databases
+	sentinel := testRange{Rec: Record{CountryShort: "-", CountryLong: "-"}}
+	var froms4 []uint32
+	for i, r := range append(t.V4[:len(t.V4):len(t.V4)], sentinel, sentinel) {
+		if len(t.V4) == 0 {
+			break
+		}
+		from := uint32(math.MaxUint32)
+		if i < len(t.V4) {
+			a := netip.MustParseAddr(r.From).As4()
+			from = binary.BigEndian.Uint32(a[:])
+			froms4 = append(froms4, from)
+		}
+		c := columns(r) // appends the strings to b
+		row := rows4 + uint32(i)*size4
+		order.PutUint32(b[row:], from)
+		copy(b[row+4:], c)
+	}
+	var froms6 []netip.Addr
+	for i, r := range append(t.V6[:len(t.V6):len(t.V6)], sentinel, sentinel) {
+		if len(t.V6) == 0 {
+			break
+		}
+		from := netip.AddrFrom16([16]byte{0: 0xff, 1: 0xff, 2: 0xff, 3: 0xff, 4: 0xff, 5: 0xff, 6: 0xff, 7: 0xff,
+			8: 0xff, 9: 0xff, 10: 0xff, 11: 0xff, 12: 0xff, 13: 0xff, 14: 0xff, 15: 0xff})
+		if i < len(t.V6) {
+			from = netip.MustParseAddr(r.From)
+			froms6 = append(froms6, from)
+		}
+		// the first column of IPv6 rows is a 128-bit number in the byte
+		// order of the file
+		a := from.As16()
+		c := columns(r)
+		row := rows6 + uint32(i)*size6
+		for j := range a {
+			if order == binary.BigEndian {
+				b[row+uint32(j)] = a[j]
+			} else {
+				b[row+uint32(j)] = a[15-j]
+			}
+		}
+		copy(b[row+16:], c)
+	}
+
+	// each index entry holds the rows of the first and the last address
+	// with its leading 16 bits
+	if idx4 != 0 {
+		for i := uint32(0); i < 65536; i++ {
+			lo := rowFor(froms4, func(f uint32) bool { return f <= i<<16 })
+			hi := rowFor(froms4, func(f uint32) bool { return f <= i<<16|0xffff })
+			order.PutUint32(b[idx4+i*8:], lo)
+			order.PutUint32(b[idx4+i*8+4:], hi)
+		}
+	}
+	if idx6 != 0 {
+		for i := uint32(0); i < 65536; i++ {
+			var first, last [16]byte
+			first[0], first[1] = byte(i>>8), byte(i)
+			last = first
+			for j := 2; j < 16; j++ {
+				last[j] = 0xff
+			}
+			lo := rowFor(froms6, func(f netip.Addr) bool { return f.Compare(netip.AddrFrom16(first)) <= 0 })
+			hi := rowFor(froms6, func(f netip.Addr) bool { return f.Compare(netip.AddrFrom16(last)) <= 0 })
+			order.PutUint32(b[idx6+i*8:], lo)
+			order.PutUint32(b[idx6+i*8+4:], hi)
+		}
+	}
+	return b
+}
+
+// the last row whose first address satisfies le, or the first row
+func rowFor[T any](froms []T, le func(T) bool) uint32 {
+	var row uint32
+	for i, f := range froms {
+		if le(f) {
+			row = uint32(i)
+		}
+	}
+	return row
+}
+
+// open opens the database described by t from memory.
+func (t testDB) open(tb testing.TB, opts ...Option) *DB {
+	tb.Helper()
+	db, err := OpenBytes(t.build(), opts...)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	tb.Cleanup(func() { db.Close() })
+	return db
+}
+
+// write writes the database described by t to a temporary file and returns
+// its path.
+func (t testDB) write(tb testing.TB) string {
+	tb.Helper()
+	path := filepath.Join(tb.TempDir(), "test.bin")
+	if err := os.WriteFile(path, t.build(), 0o644); err != nil {
+		tb.Fatal(err)
+	}
+	return path
+}
+
+// openFile opens the database described by t from a temporary file.
+func (t testDB) openFile(tb testing.TB, opts ...Option) *DB {
+	tb.Helper()
+	db, err := Open(t.write(tb), opts...)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	tb.Cleanup(func() { db.Close() })
+	return db
+}
+
+// a DB5 (country, region, city, latitude, longitude) with both tables
+var testDB5 = testDB{Type: 5, V4: testRangesV4, V6: testRangesV6}
+
+// the integer value of addr, as the search uses it
+func addrInt(addr string) *big.Int {
+	a := netip.MustParseAddr(addr).As16()
+	return new(big.Int).SetBytes(a[:])
+}
@@ -0,0 +1,35 @@
+package ip2location
+
+import (
+	"context"
+	"net"
+	"net/netip"
+)
+
+// HostRecord is the Record for one address of a resolved host.
+type HostRecord struct {
+	IP     netip.Addr
+	Record *Record
+}
+
+// GetAllHost resolves host with net.DefaultResolver and gets all fields for
+// each of its addresses, in the order the resolver returned them. It performs
+// DNS queries, bounded by ctx; it is meant for command-line and diagnostic
+// use, not for the request path.
+func (db *DB) GetAllHost(ctx context.Context, host string) ([]HostRecord, error) {
+	addrs, err := net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([]HostRecord, 0, len(addrs))
+	for _, addr := range addrs {
+		addr = addr.Unmap()
+		x, err := db.query(addr.String(), FieldAll)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, HostRecord{IP: addr, Record: x})
+	}
+	return records, nil
+}
@@ -0,0 +1,169 @@
+package ip2location
+
+import (
+	"container/list"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// defaults for NewHTTPReaderAt
+const (
+	DefaultHTTPBlockSize   = 64 << 10
+	DefaultHTTPCacheBlocks = 1024
+)
+
+// HTTPReaderAt reads a remote file with HTTP range requests, for use with
+// OpenReader when downloading the whole database is not worth it, e.g. for a
+// short job doing few lookups. Reads are served in fixed-size blocks kept in
+// a bounded LRU cache.
+//
+// Every uncached block costs a round trip, and a lookup needs about one read
+// per step of the binary search plus one per string, so lookups are slow
+// until the cache is warm. Opening the database with WithIndexCache loads the
+// index once up front and narrows each search to a few rows, which is
+// strongly advised. It is safe for concurrent use; concurrent reads of the
+// same uncached block may fetch it more than once.
+type HTTPReaderAt struct {
+	client    *http.Client
+	url       string
+	size      int64
+	blockSize int64
+
+	mu     sync.Mutex
+	blocks int
+	ll     *list.List
+	items  map[int64]*list.Element
+}
+
+type httpBlock struct {
+	n    int64 // block number
+	data []byte
+}
+
+// NewHTTPReaderAt returns a reader for the file at url, fetched with client,
+// or http.DefaultClient if nil. blockSize is the size of each range request
+// and cacheBlocks the number of blocks kept in memory; zero or negative
+// values select DefaultHTTPBlockSize and DefaultHTTPCacheBlocks. The size of
+// the file is requested right away, which also checks that the server
+// supports range requests.
+func NewHTTPReaderAt(client *http.Client, url string, blockSize, cacheBlocks int) (*HTTPReaderAt, error) {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	if blockSize <= 0 {
+		blockSize = DefaultHTTPBlockSize
+	}
+	if cacheBlocks <= 0 {
+		cacheBlocks = DefaultHTTPCacheBlocks
+	}
+	r := &HTTPReaderAt{
+		client:    client,
+		url:       url,
+		blockSize: int64(blockSize),
+		blocks:    cacheBlocks,
+		ll:        list.New(),
+		items:     make(map[int64]*list.Element, cacheBlocks),
+	}
+
+	// the total size is in the Content-Range of any partial response
+	resp, err := r.get(0, 0)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+	cr := resp.Header.Get("Content-Range")
+	i := strings.LastIndexByte(cr, '/')
+	if i < 0 {
+		return nil, fmt.Errorf("ip2location: %s: no size in Content-Range %q", url, cr)
+	}
+	r.size, err = strconv.ParseInt(cr[i+1:], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("ip2location: %s: no size in Content-Range %q", url, cr)
+	}
+	return r, nil
+}
+
+// Size returns the size of the remote file.
+func (r *HTTPReaderAt) Size() int64 {
+	return r.size
+}
+
+// ReadAt implements io.ReaderAt.
+func (r *HTTPReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, fmt.Errorf("ip2location: negative offset")
+	}
+	n := 0
+	for n < len(p) {
+		pos := off + int64(n)
+		if pos >= r.size {
+			return n, io.EOF
+		}
+		b, err := r.block(pos / r.blockSize)
+		if err != nil {
+			return n, err
+		}
+		n += copy(p[n:], b[pos%r.blockSize:])
+	}
+	return n, nil
+}
+
+// get block number bn from the cache or the server
+func (r *HTTPReaderAt) block(bn int64) ([]byte, error) {
+	r.mu.Lock()
+	if e, ok := r.items[bn]; ok {
+		r.ll.MoveToFront(e)
+		r.mu.Unlock()
+		return e.Value.(*httpBlock).data, nil
+	}
+	r.mu.Unlock()
+
+	start := bn * r.blockSize
+	end := start + r.blockSize - 1
+	if end >= r.size {
+		end = r.size - 1
+	}
+	resp, err := r.get(start, end)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	data := make([]byte, end-start+1)
+	if _, err := io.ReadFull(resp.Body, data); err != nil {
+		return nil, fmt.Errorf("ip2location: %s: reading bytes %d-%d: %w", r.url, start, end, err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.items[bn]; !ok {
+		r.items[bn] = r.ll.PushFront(&httpBlock{n: bn, data: data})
+		if r.ll.Len() > r.blocks {
+			oldest := r.ll.Back()
+			r.ll.Remove(oldest)
+			delete(r.items, oldest.Value.(*httpBlock).n)
+		}
+	}
+	return data, nil
+}
+
+// request the inclusive byte range [start, end]
+func (r *HTTPReaderAt) get(start, end int64) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodGet, r.url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", start, end))
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusPartialContent {
+		resp.Body.Close()
+		return nil, fmt.Errorf("ip2location: %s: range request answered with %s", r.url, resp.Status)
+	}
+	return resp, nil
+}
@@ -0,0 +1,160 @@
+package ip2location
+
+import (
+	"fmt"
+)
+
+// each index has an entry per 16-bit prefix, holding the low and high row
+const indexSize = 65536 * 8
+
+// load the IPv4 and IPv6 index tables into memory
+func (db *DB) loadIndex() error {
+	var err error
+	if db.meta.ipv4IndexBaseAddr > 0 {
+		db.ipv4Index, err = db.readIndexTable(db.meta.ipv4IndexBaseAddr)
+		if err != nil {
+			return err
+		}
+	}
+	if db.meta.ipv6IndexBaseAddr > 0 {
+		db.ipv6Index, err = db.readIndexTable(db.meta.ipv6IndexBaseAddr)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// read a whole index table starting at pos
+func (db *DB) readIndexTable(pos uint32) ([]uint32, error) {
+	data := make([]byte, indexSize)
+	_, err := db.readAt(data, int64(pos)-1)
+	if err != nil {
+		return nil, readErr(int64(pos)-1, err)
+	}
+	idx := make([]uint32, indexSize/4)
+	for i := range idx {
+		idx[i] = db.order.Uint32(data[i*4:])
+	}
+	return idx, nil
+}
+
+// read the low and high rows of the index entry at ipindex
+func (db *DB) readIndex(iptype uint32, ipindex uint32) (low, high uint32, err error) {
+	idx, base, count := db.ipv4Index, db.meta.ipv4IndexBaseAddr, db.meta.ipv4DatabaseCount
+	if iptype == 6 {
+		idx, base, count = db.ipv6Index, db.meta.ipv6IndexBaseAddr, db.meta.ipv6DatabaseCount
+	}
+	if idx != nil {
+		i := (ipindex - base) / 4
+		low, high = idx[i], idx[i+1]
+	} else {
+		low, err = db.readUint32(ipindex)
+		if err != nil {
+			return 0, 0, err
+		}
+		high, err = db.readUint32(ipindex + 4)
+		if err != nil {
+			return 0, 0, err
+		}
+	}
+	// searches rely on the bounds to stay within the table
+	if high > count {
+		return 0, 0, fmt.Errorf("%w: index entry at %d points past row %d", ErrCorruptDatabase, ipindex, count)
+	}
+	return low, high, nil
+}
+
+// IndexRange returns the [low, high] rows of the table the index narrows the
+// IP address down to, without searching them or reading any record. Without
+// an index the bounds are the whole table.
+func (db *DB) IndexRange(ipaddress string) (low, high uint32, err error) {
+	iptype, _, ipindex := db.checkIP(ipaddress)
+	if iptype == 0 {
+		return 0, 0, ErrInvalidAddress
+	}
+	if ipindex > 0 {
+		return db.readIndex(iptype, ipindex)
+	}
+	if iptype == 4 {
+		return 0, db.meta.ipv4DatabaseCount, nil
+	}
+	return 0, db.meta.ipv6DatabaseCount, nil
+}
+
+// disable the fields whose column lies past the row size given in the
+// header, which a header with the wrong database type or column count may
+// imply; WithStrictValidation rejects such headers instead
+func (db *DB) dropShortColumns() {
+	rowSize := db.meta.ipv4ColumnsSize // IPv6 rows are decoded past their wider first column
+	for _, c := range []struct {
+		offset  uint32
+		enabled *bool
+	}{
+		{db.countryPositionOffset, &db.countryEnabled},
+		{db.regionPositionOffset, &db.regionEnabled},
+		{db.cityPositionOffset, &db.cityEnabled},
+		{db.ispPositionOffset, &db.ispEnabled},
+		{db.latitudePositionOffset, &db.latitudeEnabled},
+		{db.longitudePositionOffset, &db.longitudeEnabled},
+		{db.domainPositionOffset, &db.domainEnabled},
+		{db.zipcodePositionOffset, &db.zipCodeEnabled},
+		{db.timeZonePositionOffset, &db.timeZoneEnabled},
+		{db.netSpeedPositionOffset, &db.netSpeedEnabled},
+		{db.iddCodePositionOffset, &db.iddCodeEnabled},
+		{db.areaCodePositionOffset, &db.areaCodeEnabled},
+		{db.weatherStationCodePositionOffset, &db.weatherStationCodeEnabled},
+		{db.weatherStationNamePositionOffset, &db.weatherStationNameEnabled},
+		{db.mccPositionOffset, &db.mccEnabled},
+		{db.mncPositionOffset, &db.mncEnabled},
+		{db.mobileBrandPositionOffset, &db.mobileBrandEnabled},
+		{db.elevationPositionOffset, &db.elevationEnabled},
+		{db.usageTypePositionOffset, &db.usageTypeEnabled},
+		{db.proxyTypePositionOffset, &db.proxyTypeEnabled},
+		{db.threatPositionOffset, &db.threatEnabled},
+		{db.providerPositionOffset, &db.providerEnabled},
+	} {
+		if *c.enabled && c.offset+4 > rowSize {
+			*c.enabled = false
+		}
+	}
+}
+
+// extra checks for WithStrictValidation
+func (db *DB) checkStrict() error {
+	size, ok := readerSize(db.file)
+	if !ok {
+		return fmt.Errorf("%w: cannot determine file size", ErrInvalidDatabase)
+	}
+	for _, base := range []uint32{db.meta.ipv4IndexBaseAddr, db.meta.ipv6IndexBaseAddr} {
+		if base > 0 && int64(base)-1+indexSize > size {
+			return fmt.Errorf("%w: index at %d ends past file size %d", ErrInvalidDatabase, base, size)
+		}
+	}
+
+	dbt := db.meta.databaseType
+	cols := typeColumns(dbt)
+	if db.meta.productCode == productIP2Proxy {
+		cols = proxyColumns[dbt]
+	}
+	if db.meta.databesColumn != cols {
+		return fmt.Errorf("%w: database type %d has %d columns, header says %d", ErrInvalidDatabase, dbt, cols, db.meta.databesColumn)
+	}
+	return nil
+}
+
+// the column count of database type dbt: the highest position it uses
+func typeColumns(dbt uint8) uint8 {
+	var cols uint8 = 1
+	for _, p := range [][25]uint8{
+		countryPosition, regionPosition, cityPosition, ispPosition, latitudePosition,
+		longitudePosition, domainPosition, zipCodePosition, timeZonePosition, netSpeedPosition,
+		iddCodePosition, areaCodePosition, weatherStationCodePosition, weatherStationNamePosition,
+		mccPosition, mncPosition, mobileBrandPosition, elevationPosition, usageTypePosition,
+	} {
+		if p[dbt] > cols {
+			cols = p[dbt]
+		}
+	}
+	return cols
+}
@@ -0,0 +1,63 @@
+package ip2location
+
+import (
+	"errors"
+	"testing"
+)
+
+// fields whose column lies past the row size in the header are dropped
+func TestDropShortColumns(t *testing.T) {
+	rec := Record{CountryShort: "AU", CountryLong: "Australia", Region: "Queensland", City: "Brisbane", Latitude: -27.5, Longitude: 153}
+	ranges4 := []testRange{{From: "0.0.0.0", Rec: rec}}
+	ranges6 := []testRange{{From: "::", Rec: rec}}
+	for _, c := range []struct {
+		name    string
+		db      testDB
+		fields  Field
+		missing Field
+	}{
+		{"DB5 as 6 columns", testDB{Type: 5, V4: ranges4, V6: ranges6}, FieldCountryShort | FieldCountryLong | FieldRegion | FieldCity | FieldLatitude | FieldLongitude, 0},
+		{"DB5 as 3 columns", testDB{Type: 5, Columns: 3, V4: ranges4, V6: ranges6}, FieldCountryShort | FieldCountryLong | FieldRegion, FieldCity},
+		{"DB5 as 1 column", testDB{Type: 5, Columns: 1, V4: ranges4, V6: ranges6}, 0, FieldCountryShort},
+		{"DB24 as 5 columns", testDB{Type: 24, Columns: 5, V4: ranges4, V6: ranges6}, FieldCountryShort | FieldCountryLong | FieldRegion | FieldCity | FieldLatitude, FieldLongitude},
+	} {
+		db := c.db.open(t)
+		if got := db.Fields(); got != c.fields {
+			t.Errorf("%s: Fields() = %v, want %v", c.name, got, c.fields)
+		}
+
+		want := Record{}
+		for _, e := range fieldNames {
+			if c.fields&e.field == 0 {
+				continue
+			}
+			str, f := want.fieldPtr(e.field)
+			recStr, recF := rec.fieldPtr(e.field)
+			if str != nil {
+				*str = *recStr
+			} else {
+				*f = *recF
+			}
+		}
+		for _, ip := range []string{"1.2.3.4", "2001:db8::1"} {
+			x, err := db.GetAll(ip)
+			if err != nil || *x != want {
+				t.Errorf("%s, %s: got %+v, %v, want %+v", c.name, ip, x, err, want)
+			}
+			if c.missing != 0 {
+				if _, err := db.queryField(ip, c.missing); err != ErrFieldUnsupported {
+					t.Errorf("%s, %s: dropped field gives %v, want ErrFieldUnsupported", c.name, ip, err)
+				}
+			}
+		}
+
+		// strict validation rejects the header instead
+		_, err := OpenBytes(c.db.build(), WithStrictValidation())
+		if c.db.Columns != 0 && !errors.Is(err, ErrInvalidDatabase) {
+			t.Errorf("%s: strict validation got %v, want ErrInvalidDatabase", c.name, err)
+		}
+		if c.db.Columns == 0 && err != nil {
+			t.Errorf("%s: strict validation got %v", c.name, err)
+		}
+	}
+}
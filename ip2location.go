@@ -5,42 +5,72 @@ import (
 	"encoding/binary"
 	"errors"
 	"fmt"
+	"io"
+	"io/fs"
+	"math"
 	"math/big"
 	"net"
 	"os"
 	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
 )
 
 const (
 	ApiVersion string = "8.0.3"
 
-	countryshort       uint32 = 0x00001
-	countrylong        uint32 = 0x00002
-	region             uint32 = 0x00004
-	city               uint32 = 0x00008
-	isp                uint32 = 0x00010
-	latitude           uint32 = 0x00020
-	longitude          uint32 = 0x00040
-	domain             uint32 = 0x00080
-	zipcode            uint32 = 0x00100
-	timezone           uint32 = 0x00200
-	netspeed           uint32 = 0x00400
-	iddcode            uint32 = 0x00800
-	areacode           uint32 = 0x01000
-	weatherstationcode uint32 = 0x02000
-	weatherstationname uint32 = 0x04000
-	mcc                uint32 = 0x08000
-	mnc                uint32 = 0x10000
-	mobilebrand        uint32 = 0x20000
-	elevation          uint32 = 0x40000
-	usagetype          uint32 = 0x80000
-
-	all uint32 = countryshort | countrylong | region | city | isp | latitude | longitude | domain | zipcode | timezone | netspeed | iddcode | areacode | weatherstationcode | weatherstationname | mcc | mnc | mobilebrand | elevation | usagetype
+	// size of the fixed header fields, in bytes
+	headerSize uint32 = 29
+
+	// size of the longest possible row: an IPv6 row with 255 columns
+	maxRowSize = 16 + 254*4
 )
 
-var (
-	ErrInvalidAddress = errors.New("Invalid IP address.")
+// Field is a bit mask selecting which Record fields a lookup fills.
+type Field uint32
+
+const (
+	FieldCountryShort       Field = 0x00001
+	FieldCountryLong        Field = 0x00002
+	FieldRegion             Field = 0x00004
+	FieldCity               Field = 0x00008
+	FieldISP                Field = 0x00010
+	FieldLatitude           Field = 0x00020
+	FieldLongitude          Field = 0x00040
+	FieldDomain             Field = 0x00080
+	FieldZipCode            Field = 0x00100
+	FieldTimeZone           Field = 0x00200
+	FieldNetSpeed           Field = 0x00400
+	FieldIDDCode            Field = 0x00800
+	FieldAreaCode           Field = 0x01000
+	FieldWeatherStationCode Field = 0x02000
+	FieldWeatherStationName Field = 0x04000
+	FieldMCC                Field = 0x08000
+	FieldMNC                Field = 0x10000
+	FieldMobileBrand        Field = 0x20000
+	FieldElevation          Field = 0x40000
+	FieldUsageType          Field = 0x80000
+	FieldProxyType          Field = 0x100000
+	FieldThreat             Field = 0x200000
+	FieldProvider           Field = 0x400000
+
+	FieldAll Field = FieldCountryShort | FieldCountryLong | FieldRegion | FieldCity | FieldISP | FieldLatitude | FieldLongitude | FieldDomain | FieldZipCode | FieldTimeZone | FieldNetSpeed | FieldIDDCode | FieldAreaCode | FieldWeatherStationCode | FieldWeatherStationName | FieldMCC | FieldMNC | FieldMobileBrand | FieldElevation | FieldUsageType | FieldProxyType | FieldThreat | FieldProvider
+)
 
+var (
+	ErrInvalidAddress     = errors.New("Invalid IP address.")
+	ErrInvalidDatabase    = errors.New("Invalid database file.")
+	ErrCorruptDatabase    = errors.New("Corrupt database file.")
+	ErrNoCoordinates      = errors.New("No coordinates for IP address.")
+	ErrIPNotFound         = errors.New("IP address not found.")
+	ErrWrongIPFamily      = errors.New("Wrong IP address family.")
+	ErrInvalidCountryCode = errors.New("Invalid country code.")
+	ErrFieldUnsupported   = errors.New("Field not supported by this database.")
+
+	// column of each field by database type, 1-based, or 0 if absent. None
+	// of the DB1-DB24 layouts has an accuracy or confidence column, so there
+	// is nothing to expose for them until IP2Location documents one.
 	countryPosition            = [25]uint8{0, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2}
 	regionPosition             = [25]uint8{0, 0, 0, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3}
 	cityPosition               = [25]uint8{0, 0, 0, 4, 4, 4, 4, 4, 4, 4, 4, 4, 4, 4, 4, 4, 4, 4, 4, 4, 4, 4, 4, 4, 4}
@@ -60,12 +90,24 @@ var (
 	mobileBrandPosition        = [25]uint8{0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 11, 18, 0, 18, 11, 18}
 	elevationPosition          = [25]uint8{0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 11, 19, 0, 19}
 	usageTypePosition          = [25]uint8{0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 12, 20}
-	maxIpv4Range               = big.NewInt(4294967295)
-	maxIpv6Range               = big.NewInt(0)
+
+	// shared and read-only: never pass them as the result of a big.Int operation
+	maxIpv4Range = big.NewInt(4294967295)
+	maxIpv6Range = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 128), big.NewInt(1))
+
+	nat64Prefix = []byte{0x00, 0x64, 0xff, 0x9b, 0, 0, 0, 0, 0, 0, 0, 0}
+
+	// scratch buffers for the read helpers, shared by all goroutines
+	buf4Pool   = sync.Pool{New: func() interface{} { return new([4]byte) }}
+	buf16Pool  = sync.Pool{New: func() interface{} { return new([16]byte) }}
+	buf256Pool = sync.Pool{New: func() interface{} { return new([256]byte) }}
+	bufRowPool = sync.Pool{New: func() interface{} { return new([maxRowSize]byte) }}
 )
 
 type DB struct {
-	file *os.File
+	file   io.ReaderAt
+	closer io.Closer
+	closed *uint32 // set once by Close; each clone has its own
 
 	// DB specific offsets
 	countryPositionOffset            uint32
@@ -87,6 +129,9 @@ type DB struct {
 	mobileBrandPositionOffset        uint32
 	elevationPositionOffset          uint32
 	usageTypePositionOffset          uint32
+	proxyTypePositionOffset          uint32
+	threatPositionOffset             uint32
+	providerPositionOffset           uint32
 
 	// Feature flags
 	countryEnabled            bool
@@ -108,13 +153,35 @@ type DB struct {
 	mobileBrandEnabled        bool
 	elevationEnabled          bool
 	usageTypeEnabled          bool
-
-	meta *dbMeta
+	proxyTypeEnabled          bool
+	threatEnabled             bool
+	providerEnabled           bool
+
+	// Options
+	order         binary.ByteOrder
+	stats         *stats // nil unless WithStats
+	ipv4Embedded  bool
+	noIndex       bool
+	strDecoder    func([]byte) string
+	privateRecord *Record   // answer for reserved addresses, if WithPrivateIPShortcut
+	observer      *observer // nil unless WithObserver
+	maxStrLen     int       // 0 unless WithMaxStringLen
+	centuryBase   int       // 0 unless WithCenturyBase
+
+	data []byte // whole file, if held in memory
+	size int64  // file size, or -1 if unknown
+
+	meta      *dbMeta
+	strCache  *strCache
+	memo      *rowMemo // nil unless WithRowMemo
+	ipv4Index []uint32 // in-memory copy of the IPv4 index, if cached
+	ipv6Index []uint32 // in-memory copy of the IPv6 index, if cached
 }
 
 type dbMeta struct {
 	databaseType      uint8
 	databesColumn     uint8
+	productCode       uint8 // 0 if the header predates product codes
 	databaseDay       uint8
 	databaseMonth     uint8
 	databaseYear      uint8
@@ -126,6 +193,7 @@ type dbMeta struct {
 	ipv6IndexBaseAddr uint32
 	ipv4ColumnsSize   uint32
 	ipv6ColumnSize    uint32
+	warmed            uint32 // set once by Warm
 }
 
 type Record struct {
@@ -149,72 +217,140 @@ type Record struct {
 	MobileBrand        string
 	Elevation          float32
 	UsageType          string
+	ProxyType          string // IP2Proxy databases only
+	Threat             string // IP2Proxy databases only
+	Provider           string // IP2Proxy databases only
 }
 
 // Open opens the database file at the given path and initializes the database.
-func Open(dbPath string) (*DB, error) {
-	maxIpv6Range.SetString("340282366920938463463374607431768211455", 10)
+func Open(dbPath string, opts ...Option) (*DB, error) {
+	o := newOptions(opts)
+	if o.shared {
+		return openShared(dbPath, o)
+	}
+	return openFile(dbPath, o)
+}
+
+// open and initialize the database file at dbPath
+func openFile(dbPath string, o *options) (*DB, error) {
+	if o.preload {
+		data, err := os.ReadFile(dbPath)
+		if err != nil {
+			return nil, err
+		}
+		return newDB(&bytesReader{data: data}, nil, o)
+	}
 
-	var err error
 	f, err := os.Open(dbPath)
 	if err != nil {
 		return nil, err
 	}
 
-	db := &DB{
-		file: f,
-		meta: &dbMeta{},
+	var r io.ReaderAt = f
+	var c io.Closer = f
+	if o.mmap {
+		m, err := mmapFile(f)
+		f.Close() // the mapping stays valid after the file is closed
+		if err != nil {
+			return nil, err
+		}
+		m.advise(o.advice) // only a hint, failure is harmless
+		r, c = m, m
 	}
 
-	db.meta.databaseType, err = db.readUint8(1)
+	db, err := newDB(r, c, o)
 	if err != nil {
+		c.Close()
 		return nil, err
 	}
-	db.meta.databesColumn, err = db.readUint8(2)
-	if err != nil {
-		return nil, err
+	return db, nil
+}
+
+// initialize the database from r; c is closed by Close and may be nil
+func newDB(r io.ReaderAt, c io.Closer, o *options) (*DB, error) {
+	var err error
+	if c != nil {
+		c = &refCloser{c: c, refs: 1} // shared with clones
 	}
-	db.meta.databaseYear, err = db.readUint8(3)
-	if err != nil {
-		return nil, err
+	db := &DB{
+		file:   r,
+		closer: c,
+		closed: new(uint32),
+		meta:   &dbMeta{},
 	}
-	db.meta.databaseMonth, err = db.readUint8(4)
-	if err != nil {
-		return nil, err
+	switch v := r.(type) {
+	case *bytesReader:
+		db.data = v.data
+	case *mmapReader:
+	default:
+		if o.readTimeout > 0 {
+			db.file = &timeoutReader{r: r, timeout: o.readTimeout}
+		}
 	}
-	db.meta.databaseDay, err = db.readUint8(5)
-	if err != nil {
-		return nil, err
+	db.size = -1
+	if size, ok := readerSize(db.file); ok {
+		db.size = size
 	}
-	db.meta.ipv4DatabaseCount, err = db.readUint32(6)
-	if err != nil {
-		return nil, err
+	db.ipv4Embedded = o.ipv4Embedded
+	db.noIndex = o.noIndex
+	db.strDecoder = o.strDecoder
+	db.privateRecord = o.privateRecord
+	db.observer = o.observer
+	db.maxStrLen = o.maxStrLen
+	db.centuryBase = o.centuryBase
+	if o.stats {
+		db.stats = &stats{}
 	}
-	db.meta.ipv4DatabaseAddr, err = db.readUint32(10)
-	if err != nil {
-		return nil, err
+	if o.stringCacheSize > 0 {
+		db.strCache = newStrCache(o.stringCacheSize)
 	}
-	db.meta.ipv6DatabaseCount, err = db.readUint32(14)
-	if err != nil {
-		return nil, err
+	if o.rowMemo {
+		db.memo = &rowMemo{}
 	}
-	db.meta.ipv6DatabaseAddr, err = db.readUint32(18)
-	if err != nil {
-		return nil, err
+
+	if o.byteOrder != nil {
+		db.order = o.byteOrder
+		err = db.readHeader()
+	} else {
+		db.order = binary.LittleEndian
+		err = db.readHeader()
+		if errors.Is(err, ErrInvalidDatabase) {
+			// a big-endian derivative fails the sanity checks when its header
+			// is read as little-endian
+			db.order = binary.BigEndian
+			if db.readHeader() != nil {
+				return nil, fmt.Errorf("%w; not valid as big-endian either", err)
+			}
+			err = nil
+		}
 	}
-	db.meta.ipv4IndexBaseAddr, err = db.readUint32(22)
 	if err != nil {
 		return nil, err
 	}
-	db.meta.ipv6IndexBaseAddr, err = db.readUint32(26)
-	if err != nil {
-		return nil, err
+
+	if db.meta.productCode == productIP2Proxy {
+		db.setProxyColumns(db.meta.databaseType)
+	} else {
+		db.setColumns(db.meta.databaseType)
+	}
+
+	if o.strictValidation {
+		if err = db.checkStrict(); err != nil {
+			return nil, err
+		}
+	}
+	db.dropShortColumns()
+	if o.indexCache && !o.noIndex {
+		if err = db.loadIndex(); err != nil {
+			return nil, err
+		}
 	}
-	db.meta.ipv4ColumnsSize = uint32(db.meta.databesColumn << 2)             // 4 bytes each column
-	db.meta.ipv6ColumnSize = uint32(16 + ((db.meta.databesColumn - 1) << 2)) // 4 bytes each column, except IPFrom column which is 16 bytes
 
-	dbt := db.meta.databaseType
+	return db, nil
+}
 
+// set the column offsets and feature flags for database type dbt
+func (db *DB) setColumns(dbt uint8) {
 	// since both IPv4 and IPv6 use 4 bytes for the below columns, can just do it once here
 	if countryPosition[dbt] != 0 {
 		db.countryPositionOffset = uint32(countryPosition[dbt]-1) << 2
@@ -292,61 +428,285 @@ func Open(dbPath string) (*DB, error) {
 		db.usageTypePositionOffset = uint32(usageTypePosition[dbt]-1) << 2
 		db.usageTypeEnabled = true
 	}
+}
 
-	return db, nil
+// read the header with the current byte order and check that it is
+// self-consistent
+func (db *DB) readHeader() error {
+	var err error
+	db.meta.databaseType, err = db.readUint8(1)
+	if err != nil {
+		return err
+	}
+	db.meta.databesColumn, err = db.readUint8(2)
+	if err != nil {
+		return err
+	}
+	db.meta.databaseYear, err = db.readUint8(3)
+	if err != nil {
+		return err
+	}
+	db.meta.databaseMonth, err = db.readUint8(4)
+	if err != nil {
+		return err
+	}
+	db.meta.databaseDay, err = db.readUint8(5)
+	if err != nil {
+		return err
+	}
+	db.meta.ipv4DatabaseCount, err = db.readUint32(6)
+	if err != nil {
+		return err
+	}
+	db.meta.ipv4DatabaseAddr, err = db.readUint32(10)
+	if err != nil {
+		return err
+	}
+	db.meta.ipv6DatabaseCount, err = db.readUint32(14)
+	if err != nil {
+		return err
+	}
+	db.meta.ipv6DatabaseAddr, err = db.readUint32(18)
+	if err != nil {
+		return err
+	}
+	db.meta.ipv4IndexBaseAddr, err = db.readUint32(22)
+	if err != nil {
+		return err
+	}
+	db.meta.ipv6IndexBaseAddr, err = db.readUint32(26)
+	if err != nil {
+		return err
+	}
+	// databases built since 2021 have a product code after the fixed fields
+	if db.meta.databaseYear >= 21 {
+		db.meta.productCode, err = db.readUint8(30)
+		if err != nil {
+			return err
+		}
+	}
+	if err = db.checkHeader(); err != nil {
+		return err
+	}
+	// widen before shifting: the column count is a byte
+	db.meta.ipv4ColumnsSize = uint32(db.meta.databesColumn) << 2       // 4 bytes each column
+	db.meta.ipv6ColumnSize = 16 + (uint32(db.meta.databesColumn)-1)<<2 // 4 bytes each column, except IPFrom column which is 16 bytes
+
+	if err = db.checkPointers(); err != nil {
+		return err
+	}
+	if err = db.checkSize(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// check that the header describes a database type we know how to read
+func (db *DB) checkHeader() error {
+	types := len(countryPosition)
+	if db.meta.productCode == productIP2Proxy {
+		types = len(proxyTypePosition)
+	}
+	if db.meta.databaseType < 1 || int(db.meta.databaseType) >= types {
+		return fmt.Errorf("%w: unsupported database type %d", ErrInvalidDatabase, db.meta.databaseType)
+	}
+	if db.meta.databesColumn < 1 {
+		return fmt.Errorf("%w: invalid column count %d", ErrInvalidDatabase, db.meta.databesColumn)
+	}
+	return nil
+}
+
+// check that the table and index addresses point past the header and the
+// IPv4 and IPv6 tables do not overlap
+func (db *DB) checkPointers() error {
+	m := db.meta
+	if m.ipv4DatabaseCount == 0 && m.ipv6DatabaseCount == 0 {
+		return fmt.Errorf("%w: no IPv4 or IPv6 records", ErrInvalidDatabase)
+	}
+	if m.ipv4DatabaseCount > 0 && m.ipv4DatabaseAddr <= headerSize {
+		return fmt.Errorf("%w: IPv4 table address %d inside header", ErrInvalidDatabase, m.ipv4DatabaseAddr)
+	}
+	if m.ipv6DatabaseCount > 0 && m.ipv6DatabaseAddr <= headerSize {
+		return fmt.Errorf("%w: IPv6 table address %d inside header", ErrInvalidDatabase, m.ipv6DatabaseAddr)
+	}
+	if m.ipv4IndexBaseAddr != 0 && m.ipv4IndexBaseAddr <= headerSize {
+		return fmt.Errorf("%w: IPv4 index address %d inside header", ErrInvalidDatabase, m.ipv4IndexBaseAddr)
+	}
+	if m.ipv6IndexBaseAddr != 0 && m.ipv6IndexBaseAddr <= headerSize {
+		return fmt.Errorf("%w: IPv6 index address %d inside header", ErrInvalidDatabase, m.ipv6IndexBaseAddr)
+	}
+
+	// offsets are 32-bit: searches read up to the first column of the row
+	// past the last one, and an index table must fit entirely
+	if end := int64(m.ipv4DatabaseAddr) + (int64(m.ipv4DatabaseCount)+1)*int64(m.ipv4ColumnsSize) + 4; m.ipv4DatabaseCount > 0 && end > math.MaxUint32 {
+		return fmt.Errorf("%w: IPv4 table ends past 4 GiB", ErrInvalidDatabase)
+	}
+	if end := int64(m.ipv6DatabaseAddr) + (int64(m.ipv6DatabaseCount)+1)*int64(m.ipv6ColumnSize) + 16; m.ipv6DatabaseCount > 0 && end > math.MaxUint32 {
+		return fmt.Errorf("%w: IPv6 table ends past 4 GiB", ErrInvalidDatabase)
+	}
+	if int64(m.ipv4IndexBaseAddr)+indexSize > math.MaxUint32 || int64(m.ipv6IndexBaseAddr)+indexSize > math.MaxUint32 {
+		return fmt.Errorf("%w: index ends past 4 GiB", ErrInvalidDatabase)
+	}
+
+	if m.ipv4DatabaseCount > 0 && m.ipv6DatabaseCount > 0 {
+		ipv4Start, ipv4End := int64(m.ipv4DatabaseAddr), int64(m.ipv4DatabaseAddr)+int64(m.ipv4DatabaseCount)*int64(m.ipv4ColumnsSize)
+		ipv6Start, ipv6End := int64(m.ipv6DatabaseAddr), int64(m.ipv6DatabaseAddr)+int64(m.ipv6DatabaseCount)*int64(m.ipv6ColumnSize)
+		if ipv4Start < ipv6End && ipv6Start < ipv4End {
+			return fmt.Errorf("%w: IPv4 table at %d overlaps IPv6 table at %d", ErrInvalidDatabase, m.ipv4DatabaseAddr, m.ipv6DatabaseAddr)
+		}
+	}
+	return nil
+}
+
+// check that the file is big enough to hold the advertised records
+func (db *DB) checkSize() error {
+	size := db.size
+	if size < 0 {
+		return nil // size unknown, nothing to check against
+	}
+
+	ipv4End := int64(db.meta.ipv4DatabaseAddr) - 1 + int64(db.meta.ipv4DatabaseCount)*int64(db.meta.ipv4ColumnsSize)
+	if db.meta.ipv4DatabaseCount > 0 && ipv4End > size {
+		return fmt.Errorf("%w: IPv4 table ends at %d, past file size %d", ErrInvalidDatabase, ipv4End, size)
+	}
+	ipv6End := int64(db.meta.ipv6DatabaseAddr) - 1 + int64(db.meta.ipv6DatabaseCount)*int64(db.meta.ipv6ColumnSize)
+	if db.meta.ipv6DatabaseCount > 0 && ipv6End > size {
+		return fmt.Errorf("%w: IPv6 table ends at %d, past file size %d", ErrInvalidDatabase, ipv6End, size)
+	}
+	return nil
+}
+
+// get the size of r if it can be determined
+func readerSize(r io.ReaderAt) (int64, bool) {
+	switch v := r.(type) {
+	case *timeoutReader:
+		return readerSize(v.r)
+	case interface{ Size() int64 }:
+		return v.Size(), true
+	case interface{ Stat() (fs.FileInfo, error) }:
+		fi, err := v.Stat()
+		if err != nil {
+			return 0, false
+		}
+		return fi.Size(), true
+	}
+	return 0, false
 }
 
-// Close closes the database.
+// Close closes the database. Calling Close more than once is a no-op.
 func (db *DB) Close() error {
-	return db.file.Close()
+	if db == nil {
+		return nil
+	}
+	if !atomic.CompareAndSwapUint32(db.closed, 0, 1) || db.closer == nil {
+		return nil
+	}
+	return db.closer.Close()
 }
 
-// get IP type and calculate IP number; calculates index too if exists
+// get IP type and calculate IP number; calculates index too if exists.
+// IPv4-mapped addresses (::ffff:1.2.3.4) are always looked up as IPv4, other
+// IPv4-embedding forms only with WithIPv4Embedded.
 func (db *DB) checkIP(ip string) (iptype uint32, ipnum *big.Int, ipindex uint32) {
-	iptype = 0
 	ipnum = big.NewInt(0)
-	ipnumtmp := big.NewInt(0)
-	ipindex = 0
+	iptype, addr := parseIP(ip, db.ipv4Embedded)
+	if iptype == 0 {
+		if db.stats != nil {
+			atomic.AddUint64(&db.stats.invalidAddresses, 1)
+		}
+	} else {
+		ipnum.SetBytes(addr)
+	}
+	ipindex = db.indexAddr(iptype, ipnum)
+	return
+}
+
+// parse ip and tell the table it is looked up in: 4 with the 4-byte address,
+// 6 with the 16-byte address, or 0 if it is not valid
+func parseIP(ip string, ipv4Embedded bool) (uint32, net.IP) {
 	ipaddress := net.ParseIP(ip)
+	if ipaddress == nil {
+		return 0, nil
+	}
+	v4 := ipaddress.To4()
+	if v4 == nil && ipv4Embedded {
+		v4 = embeddedIPv4(ipaddress)
+	}
+	if v4 != nil {
+		return 4, v4
+	}
+	if v6 := ipaddress.To16(); v6 != nil {
+		return 6, v6
+	}
+	return 0, nil
+}
 
-	if ipaddress != nil {
-		v4 := ipaddress.To4()
+// ValidIP reports whether ip is an address the Get methods accept, and its
+// family: 4 for IPv4 and IPv4-mapped IPv6 addresses, 6 for other IPv6
+// addresses, or 0 if it is not valid. It does not need a database.
+func ValidIP(ip string) (family int, ok bool) {
+	iptype, _ := parseIP(ip, false)
+	return int(iptype), iptype != 0
+}
 
-		if v4 != nil {
-			iptype = 4
-			ipnum.SetBytes(v4)
-		} else {
-			v6 := ipaddress.To16()
+// extract the IPv4 address from NAT64 (64:ff9b::/96) and 6to4 (2002::/16) addresses
+func embeddedIPv4(ip net.IP) net.IP {
+	ip = ip.To16()
+	if ip == nil {
+		return nil
+	}
+	if bytes.Equal(ip[:12], nat64Prefix) {
+		return ip[12:16]
+	}
+	if ip[0] == 0x20 && ip[1] == 0x02 {
+		return ip[2:6]
+	}
+	return nil
+}
 
-			if v6 != nil {
-				iptype = 6
-				ipnum.SetBytes(v6)
-			}
-		}
+// calculate index position for the IP number; 0 if the database has no index
+func (db *DB) indexAddr(iptype uint32, ipnum *big.Int) uint32 {
+	if db.noIndex {
+		return 0
 	}
 	if iptype == 4 {
 		if db.meta.ipv4IndexBaseAddr > 0 {
-			ipnumtmp.Rsh(ipnum, 16)
-			ipnumtmp.Lsh(ipnumtmp, 3)
-			ipindex = uint32(ipnumtmp.Add(ipnumtmp, big.NewInt(int64(db.meta.ipv4IndexBaseAddr))).Uint64())
+			return uint32(ipnum.Uint64())>>16<<3 + db.meta.ipv4IndexBaseAddr
 		}
 	} else if iptype == 6 {
 		if db.meta.ipv6IndexBaseAddr > 0 {
+			ipnumtmp := big.NewInt(0)
 			ipnumtmp.Rsh(ipnum, 112)
 			ipnumtmp.Lsh(ipnumtmp, 3)
-			ipindex = uint32(ipnumtmp.Add(ipnumtmp, big.NewInt(int64(db.meta.ipv6IndexBaseAddr))).Uint64())
+			return uint32(ipnumtmp.Add(ipnumtmp, big.NewInt(int64(db.meta.ipv6IndexBaseAddr))).Uint64())
 		}
 	}
-	return
+	return 0
+}
+
+// add the offset to a read error; reads past the end of file mean a pointer
+// in the database is bad or the file is truncated
+func readErr(off int64, err error) error {
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return fmt.Errorf("ip2location: read at offset %d: %w: %w", off, ErrCorruptDatabase, err)
+	}
+	return fmt.Errorf("ip2location: read at offset %d: %w", off, err)
 }
 
 // read byte
 func (db *DB) readUint8(pos int64) (uint8, error) {
+	if db.data != nil {
+		b, err := db.slice(pos-1, 1)
+		if err != nil {
+			return 0, err
+		}
+		return b[0], nil
+	}
 	var retval uint8
 	data := make([]byte, 1)
-	_, err := db.file.ReadAt(data, pos-1)
+	_, err := db.readAt(data, pos-1)
 	if err != nil {
-		return 0, err
+		return 0, readErr(pos-1, err)
 	}
 	retval = data[0]
 	return retval, nil
@@ -355,480 +715,966 @@ func (db *DB) readUint8(pos int64) (uint8, error) {
 // read unsigned 32-bit integer
 func (db *DB) readUint32(pos uint32) (uint32, error) {
 	pos2 := int64(pos)
-	var retval uint32
-	data := make([]byte, 4)
-	_, err := db.file.ReadAt(data, pos2-1)
-	if err != nil {
-		return 0, err
+	if db.data != nil {
+		b, err := db.slice(pos2-1, 4)
+		if err != nil {
+			return 0, err
+		}
+		return db.order.Uint32(b), nil
 	}
-	buf := bytes.NewReader(data)
-	err = binary.Read(buf, binary.LittleEndian, &retval)
+	buf := buf4Pool.Get().(*[4]byte)
+	defer buf4Pool.Put(buf)
+	_, err := db.readAt(buf[:], pos2-1)
 	if err != nil {
-		return 0, err
+		return 0, readErr(pos2-1, err)
 	}
-	return retval, nil
+	return db.order.Uint32(buf[:]), nil
 }
 
 // read unsigned 128-bit integer
 func (db *DB) readUint128(pos uint32) (*big.Int, error) {
 	pos2 := int64(pos)
 	retval := big.NewInt(0)
-	data := make([]byte, 16)
-	_, err := db.file.ReadAt(data, pos2-1)
-	if err != nil {
-		return nil, err
+	buf := buf16Pool.Get().(*[16]byte)
+	defer buf16Pool.Put(buf)
+	data := buf[:]
+	if db.data != nil {
+		b, err := db.slice(pos2-1, 16)
+		if err != nil {
+			return nil, err
+		}
+		copy(data, b)
+	} else if _, err := db.readAt(data, pos2-1); err != nil {
+		return nil, readErr(pos2-1, err)
 	}
 
-	// little endian to big endian
-	for i, j := 0, len(data)-1; i < j; i, j = i+1, j-1 {
-		data[i], data[j] = data[j], data[i]
+	// big.Int wants big endian
+	if db.order != binary.BigEndian {
+		for i, j := 0, len(data)-1; i < j; i, j = i+1, j-1 {
+			data[i], data[j] = data[j], data[i]
+		}
 	}
 	retval.SetBytes(data)
 	return retval, nil
 }
 
+// convert the raw bytes of a string with the configured decoder
+func (db *DB) decodeStr(b []byte) string {
+	if db.strDecoder != nil {
+		return db.strDecoder(b)
+	}
+	return string(b)
+}
+
+// check that a string of length strlen at pos ends within the file, does not
+// exceed WithMaxStringLen and lies outside the header, tables and indexes
+func (db *DB) checkStrLen(pos int64, strlen uint8) error {
+	if db.maxStrLen > 0 && int(strlen) > db.maxStrLen {
+		return fmt.Errorf("%w: string of length %d at offset %d longer than %d", ErrCorruptDatabase, strlen, pos, db.maxStrLen)
+	}
+	end := pos + 1 + int64(strlen)
+	if db.size >= 0 && end > db.size {
+		return fmt.Errorf("%w: string of length %d at offset %d runs past file size %d", ErrCorruptDatabase, strlen, pos, db.size)
+	}
+	if pos < int64(headerSize) {
+		return fmt.Errorf("%w: string at offset %d inside header", ErrCorruptDatabase, pos)
+	}
+
+	m := db.meta
+	for _, r := range [...]struct {
+		name       string
+		start, len int64
+	}{
+		{"IPv4 table", int64(m.ipv4DatabaseAddr) - 1, int64(m.ipv4DatabaseCount) * int64(m.ipv4ColumnsSize)},
+		{"IPv6 table", int64(m.ipv6DatabaseAddr) - 1, int64(m.ipv6DatabaseCount) * int64(m.ipv6ColumnSize)},
+		{"IPv4 index", int64(m.ipv4IndexBaseAddr) - 1, indexLen(m.ipv4IndexBaseAddr)},
+		{"IPv6 index", int64(m.ipv6IndexBaseAddr) - 1, indexLen(m.ipv6IndexBaseAddr)},
+	} {
+		if r.len > 0 && pos < r.start+r.len && r.start < end {
+			return fmt.Errorf("%w: string at offset %d overlaps the %s", ErrCorruptDatabase, pos, r.name)
+		}
+	}
+	return nil
+}
+
+// the length of the index table at base, which is 0 if there is none
+func indexLen(base uint32) int64 {
+	if base == 0 {
+		return 0
+	}
+	return indexSize
+}
+
 // read string
 func (db *DB) readStr(pos uint32) (string, error) {
+	if db.strCache != nil {
+		if str, ok := db.strCache.get(pos); ok {
+			return str, nil
+		}
+	}
+
 	pos2 := int64(pos)
+	// a corrupt pointer may point at or near the end of the file
+	if db.size >= 0 && pos2 >= db.size {
+		return "", fmt.Errorf("%w: string offset %d past file size %d", ErrCorruptDatabase, pos2, db.size)
+	}
+	if db.data != nil {
+		b, err := db.slice(pos2, 1)
+		if err != nil {
+			return "", err
+		}
+		if err := db.checkStrLen(pos2, b[0]); err != nil {
+			return "", err
+		}
+		b, err = db.slice(pos2+1, int(b[0]))
+		if err != nil {
+			return "", err
+		}
+		return db.decodeStr(b), nil
+	}
 	var retval string
-	lenbyte := make([]byte, 1)
-	_, err := db.file.ReadAt(lenbyte, pos2)
+	// the length prefix is a single byte, so any string fits in 256 bytes
+	buf := buf256Pool.Get().(*[256]byte)
+	defer buf256Pool.Put(buf)
+	_, err := db.readAt(buf[:1], pos2)
 	if err != nil {
+		return "", readErr(pos2, err)
+	}
+	strlen := buf[0]
+	if err := db.checkStrLen(pos2, strlen); err != nil {
 		return "", err
 	}
-	strlen := lenbyte[0]
-	data := make([]byte, strlen)
-	_, err = db.file.ReadAt(data, pos2+1)
+	data := buf[:strlen]
+	_, err = db.readAt(data, pos2+1)
 	if err != nil {
-		return "", err
+		return "", readErr(pos2+1, err)
+	}
+	retval = db.decodeStr(data)
+	if db.strCache != nil {
+		db.strCache.add(pos, retval)
 	}
-	retval = string(data[:strlen])
 	return retval, nil
 }
 
-// read float
-func (db *DB) readFloat(pos uint32) (float32, error) {
-	pos2 := int64(pos)
-	var retval float32
-	data := make([]byte, 4)
-	_, err := db.file.ReadAt(data, pos2-1)
-	if err != nil {
-		return 0, err
+// Query gets only the requested fields with a single search. Fields can be
+// combined, e.g. FieldCountryShort|FieldCity|FieldISP. Like GetAll, it
+// returns an empty, non-nil Record if the address is not found.
+func (db *DB) Query(ipaddress string, fields Field) (*Record, error) {
+	return db.query(ipaddress, fields)
+}
+
+// QueryInto is like Query but fills dst instead of allocating a Record. dst is
+// reset first, so fields that are not requested or not found are left empty;
+// it is also reset on error. dst must not be shared across goroutines.
+func (db *DB) QueryInto(ipaddress string, fields Field, dst *Record) error {
+	if db.observer == nil {
+		return db.lookupInto(ipaddress, fields, dst)
 	}
-	buf := bytes.NewReader(data)
-	err = binary.Read(buf, binary.LittleEndian, &retval)
-	if err != nil {
-		return 0, err
+	start := time.Now()
+	err := db.lookupInto(ipaddress, fields, dst)
+	db.observer.observe(ipaddress, start, err)
+	return err
+}
+
+// QueryInto without observing
+func (db *DB) lookupInto(ipaddress string, fields Field, dst *Record) error {
+	*dst = Record{}
+	iptype, ipno, ipindex := db.checkIP(ipaddress)
+	if iptype == 0 {
+		return ErrInvalidAddress
 	}
-	return retval, nil
+	rowoffset, found, err := db.find(iptype, ipno, ipindex)
+	if err != nil || !found {
+		return err
+	}
+	if err := db.readRecordInto(dst, iptype, rowoffset, fields); err != nil {
+		*dst = Record{}
+		return err
+	}
+	return nil
 }
 
-// get all fields
+// GetAll gets all fields. If the address is not in the database, the Record
+// is empty but not nil; use GetAllFound to tell a miss apart.
 func (db *DB) GetAll(ipaddress string) (*Record, error) {
-	return db.query(ipaddress, all)
+	return db.query(ipaddress, FieldAll)
+}
+
+// GetAllFound is like GetAll but also reports whether the address was found.
+// On a miss it returns a nil Record and false.
+func (db *DB) GetAllFound(ipaddress string) (*Record, bool, error) {
+	iptype, ipno, ipindex := db.checkIP(ipaddress)
+	if iptype == 0 {
+		return nil, false, ErrInvalidAddress
+	}
+	rowoffset, found, err := db.find(iptype, ipno, ipindex)
+	if err != nil || !found {
+		return nil, false, err
+	}
+	x, err := db.readRecord(iptype, rowoffset, FieldAll)
+	if err != nil {
+		return nil, false, err
+	}
+	return x, true, nil
+}
+
+// Contains reports whether the IP address is in a range of the database. It
+// only searches, without decoding any field.
+func (db *DB) Contains(ipaddress string) (bool, error) {
+	iptype, ipno, ipindex := db.checkIP(ipaddress)
+	if iptype == 0 {
+		return false, ErrInvalidAddress
+	}
+	_, found, err := db.find(iptype, ipno, ipindex)
+	return found, err
+}
+
+// GetAllV4 is like GetAll but returns ErrWrongIPFamily unless the address is
+// looked up as IPv4. IPv4-mapped IPv6 addresses count as IPv4.
+func (db *DB) GetAllV4(ipaddress string) (*Record, error) {
+	return db.queryFamily(ipaddress, 4)
+}
+
+// GetAllV6 is like GetAll but returns ErrWrongIPFamily unless the address is
+// looked up as IPv6.
+func (db *DB) GetAllV6(ipaddress string) (*Record, error) {
+	return db.queryFamily(ipaddress, 6)
+}
+
+// The single-field getters below each search the table and read only the
+// requested field. A handle remembers where the last address they looked up
+// was found, so getting several fields of one address in a row costs a
+// single search; interleaving addresses, or other goroutines doing so,
+// defeats this. When several fields are needed, prefer Query or GetAll.
+
+// get country code and name
+func (db *DB) GetCountry(ipaddress string) (*Record, error) {
+	return db.queryField(ipaddress, FieldCountryShort|FieldCountryLong)
 }
 
 // get country code
 func (db *DB) GetCountryShort(ipaddress string) (*Record, error) {
-	return db.query(ipaddress, countryshort)
+	return db.queryField(ipaddress, FieldCountryShort)
 }
 
 // get country name
 func (db *DB) GetCountryLong(ipaddress string) (*Record, error) {
-	return db.query(ipaddress, countrylong)
+	return db.queryField(ipaddress, FieldCountryLong)
 }
 
 // get region
 func (db *DB) GetRegion(ipaddress string) (*Record, error) {
-	return db.query(ipaddress, region)
+	return db.queryField(ipaddress, FieldRegion)
 }
 
 // get city
 func (db *DB) GetCity(ipaddress string) (*Record, error) {
-	return db.query(ipaddress, city)
+	return db.queryField(ipaddress, FieldCity)
 }
 
 // get isp
 func (db *DB) GetISP(ipaddress string) (*Record, error) {
-	return db.query(ipaddress, isp)
+	return db.queryField(ipaddress, FieldISP)
 }
 
 // get latitude
 func (db *DB) GetLatitude(ipaddress string) (*Record, error) {
-	return db.query(ipaddress, latitude)
+	return db.queryField(ipaddress, FieldLatitude)
 }
 
 // get longitude
 func (db *DB) GetLongitude(ipaddress string) (*Record, error) {
-	return db.query(ipaddress, longitude)
+	return db.queryField(ipaddress, FieldLongitude)
 }
 
 // get domain
 func (db *DB) GetDomain(ipaddress string) (*Record, error) {
-	return db.query(ipaddress, domain)
+	return db.queryField(ipaddress, FieldDomain)
 }
 
 // get zip code
 func (db *DB) GetZipCode(ipaddress string) (*Record, error) {
-	return db.query(ipaddress, zipcode)
+	return db.queryField(ipaddress, FieldZipCode)
 }
 
 // get time zone
 func (db *DB) GetTimeZone(ipaddress string) (*Record, error) {
-	return db.query(ipaddress, timezone)
+	return db.queryField(ipaddress, FieldTimeZone)
 }
 
 // get net speed
 func (db *DB) GetNetSpeed(ipaddress string) (*Record, error) {
-	return db.query(ipaddress, netspeed)
+	return db.queryField(ipaddress, FieldNetSpeed)
 }
 
 // get idd code
 func (db *DB) GetIDDCode(ipaddress string) (*Record, error) {
-	return db.query(ipaddress, iddcode)
+	return db.queryField(ipaddress, FieldIDDCode)
 }
 
 // get area code
 func (db *DB) GetAreaCode(ipaddress string) (*Record, error) {
-	return db.query(ipaddress, areacode)
+	return db.queryField(ipaddress, FieldAreaCode)
 }
 
 // get weather station code
 func (db *DB) GetWeatherStationCode(ipaddress string) (*Record, error) {
-	return db.query(ipaddress, weatherstationcode)
+	return db.queryField(ipaddress, FieldWeatherStationCode)
 }
 
 // get weather station name
 func (db *DB) GetWeatherStationName(ipaddress string) (*Record, error) {
-	return db.query(ipaddress, weatherstationname)
+	return db.queryField(ipaddress, FieldWeatherStationName)
 }
 
 // get mobile country code
 func (db *DB) GetMCC(ipaddress string) (*Record, error) {
-	return db.query(ipaddress, mcc)
+	return db.queryField(ipaddress, FieldMCC)
 }
 
 // get mobile network code
 func (db *DB) GetMNC(ipaddress string) (*Record, error) {
-	return db.query(ipaddress, mnc)
+	return db.queryField(ipaddress, FieldMNC)
 }
 
 // get mobile carrier brand
 func (db *DB) GetMobileBrand(ipaddress string) (*Record, error) {
-	return db.query(ipaddress, mobilebrand)
+	return db.queryField(ipaddress, FieldMobileBrand)
 }
 
 // get elevation
 func (db *DB) GetElevation(ipaddress string) (*Record, error) {
-	return db.query(ipaddress, elevation)
+	return db.queryField(ipaddress, FieldElevation)
 }
 
 // get usage type
 func (db *DB) GetUsageType(ipaddress string) (*Record, error) {
-	return db.query(ipaddress, usagetype)
+	return db.queryField(ipaddress, FieldUsageType)
 }
 
-// main query
-func (db *DB) query(ipaddress string, mode uint32) (*Record, error) {
-	x := &Record{} // empty record
-
-	// check IP type and return IP number & index (if exists)
-	iptype, ipno, ipindex := db.checkIP(ipaddress)
+// GetAllByUint32 gets all fields for an IPv4 address given as its integer value.
+func (db *DB) GetAllByUint32(n uint32) (*Record, error) {
+	ipno := big.NewInt(int64(n))
+	return db.queryNum(4, ipno, db.indexAddr(4, ipno), FieldAll)
+}
 
-	if iptype == 0 {
+// GetAllByBigInt gets all fields for an IPv6 address given as its integer value.
+func (db *DB) GetAllByBigInt(n *big.Int) (*Record, error) {
+	if n == nil || n.Sign() < 0 || n.Cmp(maxIpv6Range) > 0 {
 		return nil, ErrInvalidAddress
 	}
+	ipno := new(big.Int).Set(n)
+	return db.queryNum(6, ipno, db.indexAddr(6, ipno), FieldAll)
+}
+
+// main query
+func (db *DB) query(ipaddress string, mode Field) (*Record, error) {
+	if db.observer == nil {
+		return db.lookup(ipaddress, mode)
+	}
+	start := time.Now()
+	x, err := db.lookup(ipaddress, mode)
+	db.observer.observe(ipaddress, start, err)
+	return x, err
+}
+
+// query without observing
+func (db *DB) lookup(ipaddress string, mode Field) (*Record, error) {
+	// check IP type and return IP number & index (if exists)
+	iptype, ipno, ipindex := db.checkIP(ipaddress)
+
+	if iptype == 0 {
+		return nil, ErrInvalidAddress
+	}
+
+	return db.queryNum(iptype, ipno, ipindex, mode)
+}
+
+// query fields the single-field getters need, failing up front if the
+// database does not carry all of them
+func (db *DB) queryField(ipaddress string, mode Field) (*Record, error) {
+	if db.Fields()&mode != mode {
+		return nil, ErrFieldUnsupported
+	}
+	if db.observer == nil {
+		return db.lookupField(ipaddress, mode)
+	}
+	start := time.Now()
+	x, err := db.lookupField(ipaddress, mode)
+	db.observer.observe(ipaddress, start, err)
+	return x, err
+}
+
+// queryField without observing, once the fields are known to be carried
+func (db *DB) lookupField(ipaddress string, mode Field) (*Record, error) {
+	if db.memo != nil {
+		if e := db.memo.last.Load(); e != nil && e.ip == ipaddress {
+			if !e.found {
+				return &Record{}, nil // empty record
+			}
+			return db.readRecord(e.iptype, e.rowoffset, mode)
+		}
+	}
+
+	iptype, ipno, ipindex := db.checkIP(ipaddress)
+	if iptype == 0 {
+		return nil, ErrInvalidAddress
+	}
+	rowoffset, found, err := db.find(iptype, ipno, ipindex)
+	if err != nil {
+		return nil, err
+	}
+	if db.memo != nil {
+		db.memo.last.Store(&memoEntry{ip: ipaddress, iptype: iptype, rowoffset: rowoffset, found: found})
+	}
+	if !found {
+		return &Record{}, nil // empty record
+	}
+	return db.readRecord(iptype, rowoffset, mode)
+}
+
+// query all fields, requiring the address to be looked up as the given family
+func (db *DB) queryFamily(ipaddress string, family uint32) (*Record, error) {
+	iptype, ipno, ipindex := db.checkIP(ipaddress)
+	if iptype == 0 {
+		return nil, ErrInvalidAddress
+	}
+	if iptype != family {
+		return nil, ErrWrongIPFamily
+	}
+	return db.queryNum(iptype, ipno, ipindex, FieldAll)
+}
+
+// search the database for an already parsed IP number
+func (db *DB) queryNum(iptype uint32, ipno *big.Int, ipindex uint32, mode Field) (*Record, error) {
+	rowoffset, found, err := db.find(iptype, ipno, ipindex)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return &Record{}, nil // empty record
+	}
+	return db.readRecord(iptype, rowoffset, mode)
+}
+
+// row offset standing for the WithPrivateIPShortcut record, which no row can
+// start at since the tables end before 4 GiB
+const privateRow = math.MaxUint32
+
+// find the row containing ipno, without its bounds; see search for misses
+func (db *DB) find(iptype uint32, ipno *big.Int, ipindex uint32) (rowoffset uint32, found bool, err error) {
+	if db.privateRecord != nil {
+		if _, ok := reservedBlock(iptype, ipno); ok {
+			return privateRow, true, nil
+		}
+	}
+	return db.findRow(iptype, ipno, ipindex)
+}
+
+// find without the WithPrivateIPShortcut, for scans of the table
+func (db *DB) findRow(iptype uint32, ipno *big.Int, ipindex uint32) (rowoffset uint32, found bool, err error) {
+	if iptype == 4 {
+		rowoffset, _, _, found, err = db.search4(uint32(ipno.Uint64()), ipindex)
+		return rowoffset, found, err
+	}
+	rowoffset, _, _, found, err = db.searchRow(iptype, ipno, ipindex)
+	return rowoffset, found, err
+}
+
+// binary search for the row containing ipno; returns the row offset and the
+// [ipfrom, ipto) bounds of the matched range. On a miss, rowoffset is that of
+// the closest preceding row, or 0 if there is none. With
+// WithPrivateIPShortcut, reserved addresses match privateRow with the bounds
+// of their reserved block.
+func (db *DB) search(iptype uint32, ipno *big.Int, ipindex uint32) (rowoffset uint32, ipfrom, ipto *big.Int, found bool, err error) {
+	if db.privateRecord != nil {
+		if p, ok := reservedBlock(iptype, ipno); ok {
+			ipfrom, ipto = prefixBounds(p)
+			return privateRow, ipfrom, ipto, true, nil
+		}
+	}
+	return db.searchRow(iptype, ipno, ipindex)
+}
+
+// search without the WithPrivateIPShortcut
+func (db *DB) searchRow(iptype uint32, ipno *big.Int, ipindex uint32) (rowoffset uint32, ipfrom, ipto *big.Int, found bool, err error) {
+	if iptype == 4 {
+		rowoffset, from, to, found, err := db.search4(uint32(ipno.Uint64()), ipindex)
+		if err != nil || !found {
+			return rowoffset, nil, nil, found, err
+		}
+		return rowoffset, new(big.Int).SetUint64(uint64(from)), new(big.Int).SetUint64(uint64(to)), true, nil
+	}
+
+	if db.stats != nil {
+		atomic.AddUint64(&db.stats.lookups, 1)
+	}
 
-	var colsize uint32
-	var baseaddr uint32
 	var low uint32
-	var high uint32
 	var mid uint32
-	var rowoffset uint32
 	var rowoffset2 uint32
-	var err error
-	ipfrom := big.NewInt(0)
-	ipto := big.NewInt(0)
-	maxip := big.NewInt(0)
-
-	if iptype == 4 {
-		baseaddr = db.meta.ipv4DatabaseAddr
-		high = db.meta.ipv4DatabaseCount
-		maxip = maxIpv4Range
-		colsize = db.meta.ipv4ColumnsSize
-	} else {
-		baseaddr = db.meta.ipv6DatabaseAddr
-		high = db.meta.ipv6DatabaseCount
-		maxip = maxIpv6Range
-		colsize = db.meta.ipv6ColumnSize
+	baseaddr := db.meta.ipv6DatabaseAddr
+	high := db.meta.ipv6DatabaseCount
+	colsize := db.meta.ipv6ColumnSize
+
+	// the table may be absent, e.g. in IPv4-only databases
+	if high == 0 {
+		if db.stats != nil {
+			atomic.AddUint64(&db.stats.misses, 1)
+		}
+		return 0, nil, nil, false, nil
 	}
 
 	// reading index
 	if ipindex > 0 {
-		low, err = db.readUint32(ipindex)
+		low, high, err = db.readIndex(iptype, ipindex)
 		if err != nil {
-			return nil, err
-		}
-		high, err = db.readUint32(ipindex + 4)
-		if err != nil {
-			return nil, err
+			return 0, nil, nil, false, err
 		}
 	}
 
-	if ipno.Cmp(maxip) >= 0 {
-		ipno = ipno.Sub(ipno, big.NewInt(1))
+	// the top address is matched by the last range; subtract on a copy so
+	// the caller's ipno is left alone
+	if ipno.Cmp(maxIpv6Range) >= 0 {
+		ipno = new(big.Int).Sub(ipno, big.NewInt(1))
 	}
 
 	for low <= high {
-		mid = (low + high) >> 1
+		mid = low + (high-low)>>1 // low+high may overflow uint32
 		rowoffset = baseaddr + (mid * colsize)
 		rowoffset2 = rowoffset + colsize
 
-		if iptype == 4 {
-			u32, err := db.readUint32(rowoffset)
-			if err != nil {
-				return nil, err
+		ipfrom, err = db.readUint128(rowoffset)
+		if err != nil {
+			return 0, nil, nil, false, err
+		}
+		ipto, err = db.readUint128(rowoffset2)
+		if err != nil {
+			return 0, nil, nil, false, err
+		}
+
+		if ipno.Cmp(ipfrom) >= 0 && ipno.Cmp(ipto) < 0 {
+			return rowoffset, ipfrom, ipto, true, nil
+		} else {
+			if ipno.Cmp(ipfrom) < 0 {
+				if mid == 0 {
+					break // high would wrap around
+				}
+				high = mid - 1
+			} else {
+				low = mid + 1
 			}
-			ipfrom = big.NewInt(int64(u32))
-			u32, err = db.readUint32(rowoffset2)
-			if err != nil {
-				return nil, err
+		}
+	}
+	if db.stats != nil {
+		atomic.AddUint64(&db.stats.misses, 1)
+	}
+	if low > 0 {
+		return baseaddr + (low-1)*colsize, nil, nil, false, nil
+	}
+	return 0, nil, nil, false, nil
+}
+
+// search for an IPv4 address using plain integer arithmetic, which saves the
+// big.Int allocations of search on the common path
+func (db *DB) search4(ipno uint32, ipindex uint32) (rowoffset, ipfrom, ipto uint32, found bool, err error) {
+	if db.stats != nil {
+		atomic.AddUint64(&db.stats.lookups, 1)
+	}
+
+	var low uint32
+	var mid uint32
+	baseaddr := db.meta.ipv4DatabaseAddr
+	high := db.meta.ipv4DatabaseCount
+	colsize := db.meta.ipv4ColumnsSize
+
+	// the table may be absent, e.g. in IPv6-only databases
+	if high == 0 {
+		if db.stats != nil {
+			atomic.AddUint64(&db.stats.misses, 1)
+		}
+		return 0, 0, 0, false, nil
+	}
+
+	// reading index
+	if ipindex > 0 {
+		low, high, err = db.readIndex(4, ipindex)
+		if err != nil {
+			return 0, 0, 0, false, err
+		}
+	}
+
+	// the top address is matched by the last range
+	if ipno == math.MaxUint32 {
+		ipno--
+	}
+
+	for low <= high {
+		mid = low + (high-low)>>1 // low+high may overflow uint32
+		rowoffset = baseaddr + (mid * colsize)
+
+		ipfrom, err = db.readUint32(rowoffset)
+		if err != nil {
+			return 0, 0, 0, false, err
+		}
+		ipto, err = db.readUint32(rowoffset + colsize)
+		if err != nil {
+			return 0, 0, 0, false, err
+		}
+
+		if ipno >= ipfrom && ipno < ipto {
+			return rowoffset, ipfrom, ipto, true, nil
+		}
+		if ipno < ipfrom {
+			if mid == 0 {
+				break // high would wrap around
 			}
-			ipto = big.NewInt(int64(u32))
+			high = mid - 1
 		} else {
-			ipfrom, err = db.readUint128(rowoffset)
+			low = mid + 1
+		}
+	}
+	if db.stats != nil {
+		atomic.AddUint64(&db.stats.misses, 1)
+	}
+	if low > 0 {
+		return baseaddr + (low-1)*colsize, 0, 0, false, nil
+	}
+	return 0, 0, 0, false, nil
+}
+
+// read the requested fields of the row at rowoffset
+func (db *DB) readRecord(iptype uint32, rowoffset uint32, mode Field) (*Record, error) {
+	x := &Record{}
+	if err := db.readRecordInto(x, iptype, rowoffset, mode); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// read the requested fields of the row at rowoffset into x; fields not
+// requested are left untouched
+func (db *DB) readRecordInto(x *Record, iptype uint32, rowoffset uint32, mode Field) error {
+	if rowoffset == privateRow {
+		db.fillPrivate(x, mode)
+		return nil
+	}
+	row, buf, err := db.readRow(iptype, rowoffset)
+	if buf != nil {
+		defer bufRowPool.Put(buf)
+	}
+	if err != nil {
+		return err
+	}
+	return db.decodeRow(x, row, mode)
+}
+
+// read the whole row at once rather than column by column, in the form
+// decodeRow takes; a non-nil buffer holds the row and goes back to
+// bufRowPool once it is decoded
+func (db *DB) readRow(iptype uint32, rowoffset uint32) ([]byte, *[maxRowSize]byte, error) {
+	colsize := db.meta.ipv4ColumnsSize
+	if iptype == 6 {
+		colsize = db.meta.ipv6ColumnSize
+	}
+
+	var row []byte
+	var buf *[maxRowSize]byte
+	if db.data != nil {
+		b, err := db.slice(int64(rowoffset)-1, int(colsize))
+		if err != nil {
+			return nil, nil, err
+		}
+		row = b
+	} else {
+		buf = bufRowPool.Get().(*[maxRowSize]byte)
+		row = buf[:colsize]
+		if _, err := db.readAt(row, int64(rowoffset)-1); err != nil {
+			return nil, buf, readErr(int64(rowoffset)-1, err)
+		}
+	}
+
+	if iptype == 6 {
+		row = row[12:] // coz below is assuming all columns are 4 bytes, so got 12 left to go to make 16 bytes total
+	}
+	return row, buf, nil
+}
+
+// decode the requested fields of row into x, reading strings from the file;
+// row starts 4 bytes before the second column
+func (db *DB) decodeRow(x *Record, row []byte, mode Field) error {
+	var err error
+
+	// short and long country names share one pointer, long name is 3 bytes after the short one
+	if mode&(FieldCountryShort|FieldCountryLong) != 0 && db.countryEnabled {
+		u32, err := db.column(row, db.countryPositionOffset)
+		if err != nil {
+			return err
+		}
+		if mode&FieldCountryShort != 0 {
+			x.CountryShort, err = db.readStr(u32)
 			if err != nil {
-				return nil, err
+				return err
 			}
-			ipto, err = db.readUint128(rowoffset2)
+		}
+		if mode&FieldCountryLong != 0 {
+			x.CountryLong, err = db.readStr(u32 + 3)
 			if err != nil {
-				return nil, err
+				return err
 			}
 		}
+	}
 
-		if ipno.Cmp(ipfrom) >= 0 && ipno.Cmp(ipto) < 0 {
-			if iptype == 6 {
-				rowoffset = rowoffset + 12 // coz below is assuming all columns are 4 bytes, so got 12 left to go to make 16 bytes total
-			}
-
-			if mode&countryshort == 1 && db.countryEnabled {
-				u32, err := db.readUint32(rowoffset + db.countryPositionOffset)
-				if err != nil {
-					return nil, err
-				}
-				x.CountryShort, err = db.readStr(u32)
-				if err != nil {
-					return nil, err
-				}
-			}
+	if mode&FieldRegion != 0 && db.regionEnabled {
+		u32, err := db.column(row, db.regionPositionOffset)
+		if err != nil {
+			return err
+		}
+		x.Region, err = db.readStr(u32)
+		if err != nil {
+			return err
+		}
+	}
 
-			if mode&countrylong != 0 && db.countryEnabled {
-				u32, err := db.readUint32(rowoffset + db.countryPositionOffset)
-				if err != nil {
-					return nil, err
-				}
-				x.CountryLong, err = db.readStr(u32 + 3)
-				if err != nil {
-					return nil, err
-				}
-			}
+	if mode&FieldCity != 0 && db.cityEnabled {
+		u32, err := db.column(row, db.cityPositionOffset)
+		if err != nil {
+			return err
+		}
+		x.City, err = db.readStr(u32)
+		if err != nil {
+			return err
+		}
+	}
 
-			if mode&region != 0 && db.regionEnabled {
-				u32, err := db.readUint32(rowoffset + db.regionPositionOffset)
-				if err != nil {
-					return nil, err
-				}
-				x.Region, err = db.readStr(u32)
-				if err != nil {
-					return nil, err
-				}
-			}
+	if mode&FieldISP != 0 && db.ispEnabled {
+		u32, err := db.column(row, db.ispPositionOffset)
+		if err != nil {
+			return err
+		}
+		x.Isp, err = db.readStr(u32)
+		if err != nil {
+			return err
+		}
+	}
 
-			if mode&city != 0 && db.cityEnabled {
-				u32, err := db.readUint32(rowoffset + db.cityPositionOffset)
-				if err != nil {
-					return nil, err
-				}
-				x.City, err = db.readStr(u32)
-				if err != nil {
-					return nil, err
-				}
-			}
+	if mode&FieldLatitude != 0 && db.latitudeEnabled {
+		x.Latitude, err = db.floatColumn(row, db.latitudePositionOffset)
+		if err != nil {
+			return err
+		}
+	}
 
-			if mode&isp != 0 && db.ispEnabled {
-				u32, err := db.readUint32(rowoffset + db.ispPositionOffset)
-				if err != nil {
-					return nil, err
-				}
-				x.Isp, err = db.readStr(u32)
-				if err != nil {
-					return nil, err
-				}
-			}
+	if mode&FieldLongitude != 0 && db.longitudeEnabled {
+		x.Longitude, err = db.floatColumn(row, db.longitudePositionOffset)
+		if err != nil {
+			return err
+		}
+	}
 
-			if mode&latitude != 0 && db.latitudeEnabled {
-				x.Latitude, err = db.readFloat(rowoffset + db.latitudePositionOffset)
-				if err != nil {
-					return nil, err
-				}
-			}
+	if mode&FieldDomain != 0 && db.domainEnabled {
+		u32, err := db.column(row, db.domainPositionOffset)
+		if err != nil {
+			return err
+		}
+		x.Domain, err = db.readStr(u32)
+		if err != nil {
+			return err
+		}
+	}
 
-			if mode&longitude != 0 && db.longitudeEnabled {
-				x.Longitude, err = db.readFloat(rowoffset + db.longitudePositionOffset)
-				if err != nil {
-					return nil, err
-				}
-			}
+	if mode&FieldZipCode != 0 && db.zipCodeEnabled {
+		u32, err := db.column(row, db.zipcodePositionOffset)
+		if err != nil {
+			return err
+		}
+		x.Zipcode, err = db.readStr(u32)
+		if err != nil {
+			return err
+		}
+	}
 
-			if mode&domain != 0 && db.domainEnabled {
-				u32, err := db.readUint32(rowoffset + db.domainPositionOffset)
-				if err != nil {
-					return nil, err
-				}
-				x.Domain, err = db.readStr(u32)
-				if err != nil {
-					return nil, err
-				}
-			}
+	if mode&FieldTimeZone != 0 && db.timeZoneEnabled {
+		u32, err := db.column(row, db.timeZonePositionOffset)
+		if err != nil {
+			return err
+		}
+		x.TimeZone, err = db.readStr(u32)
+		if err != nil {
+			return err
+		}
+	}
 
-			if mode&zipcode != 0 && db.zipCodeEnabled {
-				u32, err := db.readUint32(rowoffset + db.zipcodePositionOffset)
-				if err != nil {
-					return nil, err
-				}
-				x.Zipcode, err = db.readStr(u32)
-				if err != nil {
-					return nil, err
-				}
-			}
+	if mode&FieldNetSpeed != 0 && db.netSpeedEnabled {
+		u32, err := db.column(row, db.netSpeedPositionOffset)
+		if err != nil {
+			return err
+		}
+		x.NetSpeed, err = db.readStr(u32)
+		if err != nil {
+			return err
+		}
+	}
 
-			if mode&timezone != 0 && db.timeZoneEnabled {
-				u32, err := db.readUint32(rowoffset + db.timeZonePositionOffset)
-				if err != nil {
-					return nil, err
-				}
-				x.TimeZone, err = db.readStr(u32)
-				if err != nil {
-					return nil, err
-				}
-			}
+	if mode&FieldIDDCode != 0 && db.iddCodeEnabled {
+		u32, err := db.column(row, db.iddCodePositionOffset)
+		if err != nil {
+			return err
+		}
+		x.IddCode, err = db.readStr(u32)
+		if err != nil {
+			return err
+		}
+	}
 
-			if mode&netspeed != 0 && db.netSpeedEnabled {
-				u32, err := db.readUint32(rowoffset + db.netSpeedPositionOffset)
-				if err != nil {
-					return nil, err
-				}
-				x.NetSpeed, err = db.readStr(u32)
-				if err != nil {
-					return nil, err
-				}
-			}
+	if mode&FieldAreaCode != 0 && db.areaCodeEnabled {
+		u32, err := db.column(row, db.areaCodePositionOffset)
+		if err != nil {
+			return err
+		}
+		x.Areacode, err = db.readStr(u32)
+		if err != nil {
+			return err
+		}
+	}
 
-			if mode&iddcode != 0 && db.iddCodeEnabled {
-				u32, err := db.readUint32(rowoffset + db.iddCodePositionOffset)
-				x.IddCode, err = db.readStr(u32)
-				if err != nil {
-					return nil, err
-				}
-			}
+	if mode&FieldWeatherStationCode != 0 && db.weatherStationCodeEnabled {
+		u32, err := db.column(row, db.weatherStationCodePositionOffset)
+		if err != nil {
+			return err
+		}
+		x.WeatherStationCode, err = db.readStr(u32)
+		if err != nil {
+			return err
+		}
+	}
 
-			if mode&areacode != 0 && db.areaCodeEnabled {
-				u32, err := db.readUint32(rowoffset + db.areaCodePositionOffset)
-				if err != nil {
-					return nil, err
-				}
-				x.Areacode, err = db.readStr(u32)
-				if err != nil {
-					return nil, err
-				}
-			}
+	if mode&FieldWeatherStationName != 0 && db.weatherStationNameEnabled {
+		u32, err := db.column(row, db.weatherStationNamePositionOffset)
+		if err != nil {
+			return err
+		}
+		x.WeatherStationName, err = db.readStr(u32)
+		if err != nil {
+			return err
+		}
+	}
 
-			if mode&weatherstationcode != 0 && db.weatherStationCodeEnabled {
-				u32, err := db.readUint32(rowoffset + db.weatherStationCodePositionOffset)
-				if err != nil {
-					return nil, err
-				}
-				x.WeatherStationCode, err = db.readStr(u32)
-				if err != nil {
-					return nil, err
-				}
-			}
+	if mode&FieldMCC != 0 && db.mccEnabled {
+		u32, err := db.column(row, db.mccPositionOffset)
+		if err != nil {
+			return err
+		}
+		x.Mcc, err = db.readStr(u32)
+		if err != nil {
+			return err
+		}
+	}
 
-			if mode&weatherstationname != 0 && db.weatherStationNameEnabled {
-				u32, err := db.readUint32(rowoffset + db.weatherStationNamePositionOffset)
-				if err != nil {
-					return nil, err
-				}
-				x.WeatherStationName, err = db.readStr(u32)
-				if err != nil {
-					return nil, err
-				}
-			}
+	if mode&FieldMNC != 0 && db.mncEnabled {
+		u32, err := db.column(row, db.mncPositionOffset)
+		if err != nil {
+			return err
+		}
+		x.Mnc, err = db.readStr(u32)
+		if err != nil {
+			return err
+		}
+	}
 
-			if mode&mcc != 0 && db.mccEnabled {
-				u32, err := db.readUint32(rowoffset + db.mccPositionOffset)
-				if err != nil {
-					return nil, err
-				}
-				x.Mcc, err = db.readStr(u32)
-			}
+	if mode&FieldMobileBrand != 0 && db.mobileBrandEnabled {
+		u32, err := db.column(row, db.mobileBrandPositionOffset)
+		if err != nil {
+			return err
+		}
+		x.MobileBrand, err = db.readStr(u32)
+		if err != nil {
+			return err
+		}
+	}
 
-			if mode&mnc != 0 && db.mncEnabled {
-				u32, err := db.readUint32(rowoffset + db.mncPositionOffset)
-				if err != nil {
-					return nil, err
-				}
-				x.Mnc, err = db.readStr(u32)
-				if err != nil {
-					return nil, err
-				}
+	if mode&FieldElevation != 0 && db.elevationEnabled {
+		u32, err := db.column(row, db.elevationPositionOffset)
+		if err != nil {
+			return err
+		}
+		str, err := db.readStr(u32)
+		if err != nil {
+			return err
+		}
+		// unlike latitude and longitude, elevation is stored as a string;
+		// "-" marks a row without elevation data
+		if str != "" && str != "-" {
+			f, err := strconv.ParseFloat(str, 32)
+			if err != nil {
+				return fmt.Errorf("ip2location: elevation at offset %d: %w: %w", u32, ErrCorruptDatabase, err)
 			}
+			x.Elevation = float32(f)
+		}
+	}
 
-			if mode&mobilebrand != 0 && db.mobileBrandEnabled {
-				u32, err := db.readUint32(rowoffset + db.mobileBrandPositionOffset)
-				if err != nil {
-					return nil, err
-				}
-				x.MobileBrand, err = db.readStr(u32)
-				if err != nil {
-					return nil, err
-				}
-			}
+	if mode&FieldUsageType != 0 && db.usageTypeEnabled {
+		u32, err := db.column(row, db.usageTypePositionOffset)
+		if err != nil {
+			return err
+		}
+		x.UsageType, err = db.readStr(u32)
+		if err != nil {
+			return err
+		}
+	}
 
-			if mode&elevation != 0 && db.elevationEnabled {
-				u32, err := db.readUint32(rowoffset + db.elevationPositionOffset)
-				if err != nil {
-					return nil, err
-				}
-				str, err := db.readStr(u32)
-				if err != nil {
-					return nil, err
-				}
-				f, _ := strconv.ParseFloat(str, 32)
-				x.Elevation = float32(f)
-			}
+	if mode&FieldProxyType != 0 && db.proxyTypeEnabled {
+		u32, err := db.column(row, db.proxyTypePositionOffset)
+		if err != nil {
+			return err
+		}
+		x.ProxyType, err = db.readStr(u32)
+		if err != nil {
+			return err
+		}
+	}
 
-			if mode&usagetype != 0 && db.usageTypeEnabled {
-				u32, err := db.readUint32(rowoffset + db.usageTypePositionOffset)
-				if err != nil {
-					return nil, err
-				}
-				x.UsageType, err = db.readStr(u32)
-				if err != nil {
-					return nil, err
-				}
-			}
+	if mode&FieldThreat != 0 && db.threatEnabled {
+		u32, err := db.column(row, db.threatPositionOffset)
+		if err != nil {
+			return err
+		}
+		x.Threat, err = db.readStr(u32)
+		if err != nil {
+			return err
+		}
+	}
 
-			return x, nil
-		} else {
-			if ipno.Cmp(ipfrom) < 0 {
-				high = mid - 1
-			} else {
-				low = mid + 1
-			}
+	if mode&FieldProvider != 0 && db.providerEnabled {
+		u32, err := db.column(row, db.providerPositionOffset)
+		if err != nil {
+			return err
+		}
+		x.Provider, err = db.readStr(u32)
+		if err != nil {
+			return err
 		}
 	}
-	return x, nil
+
+	return nil
+}
+
+// read the 4-byte column at off of row
+func (db *DB) column(row []byte, off uint32) (uint32, error) {
+	if int(off)+4 > len(row) {
+		return 0, fmt.Errorf("%w: column at %d past row size %d", ErrCorruptDatabase, off, len(row))
+	}
+	return db.order.Uint32(row[off:]), nil
+}
+
+// read the float column at off of row
+func (db *DB) floatColumn(row []byte, off uint32) (float32, error) {
+	u32, err := db.column(row, off)
+	if err != nil {
+		return 0, err
+	}
+	return math.Float32frombits(u32), nil
 }
 
 func (x Record) String() string {
@@ -838,8 +1684,8 @@ func (x Record) String() string {
 	fmt.Fprintf(buf, "region: %s\n", x.Region)
 	fmt.Fprintf(buf, "city: %s\n", x.City)
 	fmt.Fprintf(buf, "isp: %s\n", x.Isp)
-	fmt.Fprintf(buf, "latitude: %file\n", x.Latitude)
-	fmt.Fprintf(buf, "longitude: %file\n", x.Longitude)
+	fmt.Fprintf(buf, "latitude: %f\n", x.Latitude)
+	fmt.Fprintf(buf, "longitude: %f\n", x.Longitude)
 	fmt.Fprintf(buf, "domain: %s\n", x.Domain)
 	fmt.Fprintf(buf, "zipcode: %s\n", x.Zipcode)
 	fmt.Fprintf(buf, "timezone: %s\n", x.TimeZone)
@@ -851,7 +1697,28 @@ func (x Record) String() string {
 	fmt.Fprintf(buf, "mcc: %s\n", x.Mcc)
 	fmt.Fprintf(buf, "mnc: %s\n", x.Mnc)
 	fmt.Fprintf(buf, "mobilebrand: %s\n", x.MobileBrand)
-	fmt.Fprintf(buf, "elevation: %file\n", x.Elevation)
+	fmt.Fprintf(buf, "elevation: %f\n", x.Elevation)
 	fmt.Fprintf(buf, "usagetype: %s\n", x.UsageType)
+	if x.ProxyType != "" || x.Threat != "" || x.Provider != "" {
+		fmt.Fprintf(buf, "proxytype: %s\n", x.ProxyType)
+		fmt.Fprintf(buf, "threat: %s\n", x.Threat)
+		fmt.Fprintf(buf, "provider: %s\n", x.Provider)
+	}
+	return buf.String()
+}
+
+// StringCompact is like String but omits empty strings and zero floats, which
+// keeps logs short for databases that carry few fields.
+func (x Record) StringCompact() string {
+	buf := &bytes.Buffer{}
+	for _, e := range fieldNames {
+		if str, f := x.fieldPtr(e.field); str != nil {
+			if *str != "" {
+				fmt.Fprintf(buf, "%s: %s\n", e.label, *str)
+			}
+		} else if *f != 0 {
+			fmt.Fprintf(buf, "%s: %f\n", e.label, *f)
+		}
+	}
 	return buf.String()
 }
@@ -0,0 +1,313 @@
+package ip2location
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+	"sync"
+	"testing"
+)
+
+func TestGetAll(t *testing.T) {
+	for _, index := range []bool{false, true} {
+		d := testDB5
+		d.Index = index
+		db := d.open(t)
+		for _, c := range []struct {
+			ip   string
+			want Record
+		}{
+			{"1.0.0.5", testRangesV4[1].Rec},
+			{"1.0.1.200", testRangesV4[2].Rec},
+			{"8.8.8.8", testRangesV4[3].Rec},
+			{"0.1.2.3", testRangesV4[0].Rec},
+			{"2001:4860:4860::8888", testRangesV6[1].Rec},
+			{"2400:cb00::1", testRangesV6[3].Rec},
+			{"::ffff:8.8.8.8", testRangesV4[3].Rec},
+		} {
+			x, err := db.GetAll(c.ip)
+			if err != nil {
+				t.Fatalf("index %v, %s: %v", index, c.ip, err)
+			}
+			if *x != c.want {
+				t.Errorf("index %v, %s: got %+v, want %+v", index, c.ip, *x, c.want)
+			}
+		}
+		if _, err := db.GetAll("not an address"); err != ErrInvalidAddress {
+			t.Errorf("malformed address: got %v, want ErrInvalidAddress", err)
+		}
+	}
+}
+
+// Open used to reset a package-level big.Int that running lookups read;
+// run with -race
+func TestConcurrentOpenAndLookup(t *testing.T) {
+	data := testDB5.build()
+	shared := testDB5.open(t)
+
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for range 20 {
+				db, err := OpenBytes(data)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				db.Close()
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for range 50 {
+				for _, ip := range benchIPs {
+					if _, err := shared.GetAll(ip); err != nil {
+						t.Error(err)
+						return
+					}
+					if _, err := shared.Query(ip, FieldCountryShort|FieldCity); err != nil {
+						t.Error(err)
+						return
+					}
+				}
+				if _, err := shared.GetAll("255.255.255.255"); err != nil {
+					t.Error(err)
+					return
+				}
+				if _, err := shared.GetAll("ffff:ffff:ffff:ffff:ffff:ffff:ffff:ffff"); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// the fixed-size reads take their buffers from pools and readStr allocates
+// only the string it returns
+func TestReadAllocs(t *testing.T) {
+	db := testDB5.openFile(t)
+	_, row, err := db.locate("1.0.0.5")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ptr, err := db.readUint32(row + db.countryPositionOffset)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range []struct {
+		name string
+		max  float64
+		read func() error
+	}{
+		{"readUint32", 0, func() error { _, err := db.readUint32(row); return err }},
+		{"readStr", 1, func() error { _, err := db.readStr(ptr + 3); return err }},
+	} {
+		if n := testing.AllocsPerRun(100, func() {
+			if err := c.read(); err != nil {
+				t.Fatal(err)
+			}
+		}); n > c.max {
+			t.Errorf("%s: %v allocs per run, want at most %v", c.name, n, c.max)
+		}
+	}
+}
+
+func BenchmarkReadStr(b *testing.B) {
+	db := testDB5.openFile(b)
+	_, row, err := db.locate("1.0.0.5")
+	if err != nil {
+		b.Fatal(err)
+	}
+	ptr, err := db.readUint32(row + db.countryPositionOffset)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := db.readStr(ptr + 3); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkReadUint128(b *testing.B) {
+	db := testDB5.openFile(b)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := db.readUint128(db.meta.ipv6DatabaseAddr); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// hugeDB is a DB1 whose IPv4 table ends just short of 4 GiB, generated on
+// the fly: row i covers the 8 addresses from i*8, all in country XX
+type hugeDB struct {
+	count uint32
+}
+
+const hugeRows = 64 // offset of the first row
+
+func (h hugeDB) strAt() int64 { return hugeRows + int64(h.count+2)*8 }
+
+func (h hugeDB) ReadAt(p []byte, off int64) (int, error) {
+	var hdr [64]byte
+	hdr[0], hdr[1], hdr[2], hdr[3], hdr[4] = 1, 2, 24, 5, 1
+	binary.LittleEndian.PutUint32(hdr[5:], h.count)
+	binary.LittleEndian.PutUint32(hdr[9:], hugeRows+1)
+	country := []byte{2, 'X', 'X', 2, 'X', 'X'}
+
+	for i := range p {
+		pos := off + int64(i)
+		switch {
+		case pos < hugeRows:
+			p[i] = hdr[pos]
+		case pos < h.strAt():
+			row, col := (pos-hugeRows)/8, (pos-hugeRows)%8
+			var b [8]byte
+			from := uint32(math.MaxUint32)
+			if row < int64(h.count) {
+				from = uint32(row) * 8
+			}
+			binary.LittleEndian.PutUint32(b[:], from)
+			binary.LittleEndian.PutUint32(b[4:], uint32(h.strAt()))
+			p[i] = b[col]
+		case pos < h.strAt()+int64(len(country)):
+			p[i] = country[pos-h.strAt()]
+		default:
+			return i, io.EOF
+		}
+	}
+	return len(p), nil
+}
+
+// the search midpoint of row numbers near the uint32 limit must not wrap
+func TestSearchHugeTable(t *testing.T) {
+	h := hugeDB{count: 1<<29 - 16}
+	db, err := OpenReader(h)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range []struct{ ip, from, to string }{
+		{"0.0.0.3", "0.0.0.0", "0.0.0.7"},
+		{"8.8.8.8", "8.8.8.8", "8.8.8.15"},
+		{"200.1.2.3", "200.1.2.0", "200.1.2.7"},
+		{"255.255.255.119", "255.255.255.112", "255.255.255.119"},
+		{"255.255.255.120", "255.255.255.120", "255.255.255.255"},
+		{"255.255.255.255", "255.255.255.120", "255.255.255.255"},
+	} {
+		r, err := db.GetAllRange(c.ip)
+		if err != nil {
+			t.Fatal(c.ip, err)
+		}
+		if r.IPFrom.String() != c.from || r.IPTo.String() != c.to || r.Record.CountryShort != "XX" {
+			t.Errorf("%s: got %v-%v %q, want %s-%s", c.ip, r.IPFrom, r.IPTo, r.Record.CountryShort, c.from, c.to)
+		}
+	}
+}
+
+// a table whose rows would run past the 32-bit offsets is rejected at open
+// rather than wrapping during searches; the byte order is fixed since the
+// swapped header would look valid
+func TestTablePast4GiB(t *testing.T) {
+	for _, count := range []uint32{1<<29 - 8, 1 << 30, math.MaxUint32} {
+		_, err := OpenReader(hugeDB{count: count}, WithByteOrder(binary.LittleEndian))
+		if !errors.Is(err, ErrInvalidDatabase) {
+			t.Errorf("count %d: got %v, want ErrInvalidDatabase", count, err)
+		}
+	}
+
+	// the same header with a known size fails the size check instead
+	b := make([]byte, 4096)
+	if _, err := (hugeDB{count: 1 << 30}).ReadAt(b[:64], 0); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := OpenBytes(b, WithByteOrder(binary.LittleEndian)); !errors.Is(err, ErrInvalidDatabase) {
+		t.Errorf("OpenBytes: got %v, want ErrInvalidDatabase", err)
+	}
+}
+
+func TestElevation(t *testing.T) {
+	row := func(from, elevation string) testRange {
+		return testRange{From: from, Rec: Record{CountryShort: "AU", CountryLong: "Australia"}, Elevation: elevation}
+	}
+	d := testDB{Type: 21, V4: []testRange{
+		row("0.0.0.0", "-"),
+		row("1.0.0.0", "28"),
+		row("1.0.1.0", "-12.5"),
+		row("1.0.2.0", "-0.25"),
+		row("1.0.3.0", "abc"),
+		row("1.0.4.0", "12m"),
+		row("1.0.5.0", ""),
+	}}
+	db := d.open(t)
+	for _, c := range []struct {
+		ip   string
+		want float32
+		bad  bool
+	}{
+		{"0.1.2.3", 0, false},
+		{"1.0.0.1", 28, false},
+		{"1.0.1.1", -12.5, false},
+		{"1.0.2.1", -0.25, false},
+		{"1.0.3.1", 0, true},
+		{"1.0.4.1", 0, true},
+		{"1.0.5.1", 0, false},
+	} {
+		got, err := db.Elevation(c.ip)
+		if c.bad {
+			if !errors.Is(err, ErrCorruptDatabase) {
+				t.Errorf("%s: got %v, %v, want ErrCorruptDatabase", c.ip, got, err)
+			}
+			continue
+		}
+		if err != nil || got != c.want {
+			t.Errorf("%s: got %v, %v, want %v", c.ip, got, err, c.want)
+		}
+		if x, err := db.GetAll(c.ip); err != nil || x.Elevation != c.want {
+			t.Errorf("%s: GetAll got %v, %v, want %v", c.ip, x, err, c.want)
+		}
+	}
+}
+
+func TestByteOrder(t *testing.T) {
+	ips := []string{"0.1.2.3", "1.0.0.5", "1.0.1.200", "8.8.8.8", "2001:4860:4860::8888", "2400:cb00::1", "3000::1"}
+	for _, d := range []testDB{
+		{Type: 5, V4: testRangesV4, V6: testRangesV6},
+		{Type: 5, V4: testRangesV4, V6: testRangesV6, Index: true},
+		{Type: 24, V4: testRangesV4, V6: testRangesV6, Index: true},
+	} {
+		le := d.open(t)
+		d.Order = binary.BigEndian
+		for _, opts := range [][]Option{nil, {WithByteOrder(binary.BigEndian)}} {
+			be := d.open(t, opts...)
+			if be.order != binary.BigEndian {
+				t.Errorf("DB%d, %d options: read as %v", d.Type, len(opts), be.order)
+			}
+			for _, ip := range ips {
+				want, err := le.GetAll(ip)
+				if err != nil {
+					t.Fatal(err)
+				}
+				x, err := be.GetAll(ip)
+				if err != nil {
+					t.Errorf("DB%d index %v, %s: %v", d.Type, d.Index, ip, err)
+					continue
+				}
+				if *x != *want {
+					t.Errorf("DB%d index %v, %s: got %+v, want %+v", d.Type, d.Index, ip, *x, *want)
+				}
+			}
+		}
+
+		// a big-endian file forced to little-endian fails the header checks
+		if _, err := OpenBytes(d.build(), WithByteOrder(binary.LittleEndian)); !errors.Is(err, ErrInvalidDatabase) {
+			t.Errorf("DB%d as little-endian: err = %v, want ErrInvalidDatabase", d.Type, err)
+		}
+	}
+}
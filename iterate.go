@@ -0,0 +1,63 @@
+package ip2location
+
+import (
+	"math/big"
+	"net/netip"
+)
+
+// Iterate calls fn for every range in the database, first the IPv4 table and
+// then the IPv6 table, in ascending order. from and to are inclusive bounds,
+// as in RangeRecord. Returning false from fn stops the iteration. Iterate
+// returns the first read error, if any.
+func (db *DB) Iterate(fn func(from, to netip.Addr, rec *Record) bool) error {
+	for _, iptype := range []uint32{4, 6} {
+		more, err := db.iterateRows(iptype, 0, fn)
+		if err != nil || !more {
+			return err
+		}
+	}
+	return nil
+}
+
+// walk the rows of one table from row start; reports whether fn asked for more
+func (db *DB) iterateRows(iptype, start uint32, fn func(from, to netip.Addr, rec *Record) bool) (bool, error) {
+	baseaddr, count, colsize := db.meta.ipv4DatabaseAddr, db.meta.ipv4DatabaseCount, db.meta.ipv4ColumnsSize
+	maxip := maxIpv4Range
+	read := func(pos uint32) (*big.Int, error) {
+		u32, err := db.readUint32(pos)
+		if err != nil {
+			return nil, err
+		}
+		return big.NewInt(int64(u32)), nil
+	}
+	if iptype == 6 {
+		baseaddr, count, colsize = db.meta.ipv6DatabaseAddr, db.meta.ipv6DatabaseCount, db.meta.ipv6ColumnSize
+		maxip = maxIpv6Range
+		read = db.readUint128
+	}
+
+	for i := start; i < count; i++ {
+		rowoffset := baseaddr + i*colsize
+		ipfrom, err := read(rowoffset)
+		if err != nil {
+			return false, err
+		}
+		// the table may end with a sentinel row starting at the top address
+		if ipfrom.Cmp(maxip) >= 0 {
+			break
+		}
+		ipto, err := read(rowoffset + colsize)
+		if err != nil {
+			return false, err
+		}
+		x, err := db.readRecord(iptype, rowoffset, FieldAll)
+		if err != nil {
+			return false, err
+		}
+		from, to := rangeAddrs(iptype, ipfrom, ipto)
+		if !fn(from, to, x) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
@@ -0,0 +1,66 @@
+package ip2location
+
+import "strconv"
+
+// Location is the subset of a Record used for geo-personalization.
+type Location struct {
+	CountryShort string
+	CountryLong  string
+	Region       string
+	City         string
+}
+
+// GetLocation gets country, region and city with a single search, without
+// reading any of the other fields. Fields the database does not carry are empty.
+func (db *DB) GetLocation(ipaddress string) (*Location, error) {
+	x, err := db.query(ipaddress, FieldCountryShort|FieldCountryLong|FieldRegion|FieldCity)
+	if err != nil {
+		return nil, err
+	}
+	return &Location{
+		CountryShort: x.CountryShort,
+		CountryLong:  x.CountryLong,
+		Region:       x.Region,
+		City:         x.City,
+	}, nil
+}
+
+// HasCoordinates reports whether x has a location. Coordinates of exactly 0,0
+// are the databases' placeholder for an unknown location, or the database has
+// no coordinate columns, so both count as absent.
+func (x *Record) HasCoordinates() bool {
+	return x.Latitude != 0 || x.Longitude != 0
+}
+
+// Point is a location in decimal degrees.
+type Point struct {
+	Lat, Lon float64
+}
+
+// Point returns the location of x, with ok false if it has none, see
+// HasCoordinates. Coordinates are widened as by Coordinates64.
+func (x *Record) Point() (p Point, ok bool) {
+	if !x.HasCoordinates() {
+		return Point{}, false
+	}
+	p.Lat, p.Lon = x.Coordinates64()
+	return p, true
+}
+
+// DistanceKm returns the great-circle distance in kilometers between p and q.
+func (p Point) DistanceKm(q Point) float64 {
+	return haversine(p.Lat, p.Lon, q.Lat, q.Lon)
+}
+
+// Coordinates64 returns the latitude and longitude as float64. The database
+// stores float32 values; they are widened through their shortest decimal form,
+// so 37.4 comes back as 37.4 rather than 37.400001525878906.
+func (x *Record) Coordinates64() (lat, lon float64) {
+	return widenFloat32(x.Latitude), widenFloat32(x.Longitude)
+}
+
+// convert f to the float64 closest to its shortest decimal representation
+func widenFloat32(f float32) float64 {
+	d, _ := strconv.ParseFloat(strconv.FormatFloat(float64(f), 'g', -1, 32), 64)
+	return d
+}
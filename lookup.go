@@ -0,0 +1,44 @@
+package ip2location
+
+// LookupResult is a Record together with the fields the database carries,
+// which tells a field that is empty for this address apart from one the
+// database type does not have at all, such as City on DB1 or coordinates
+// below DB5.
+type LookupResult struct {
+	Record    *Record
+	Available Field // fields the database carries, as returned by DB.Fields
+	Found     bool  // whether the address is in the database
+}
+
+// Supported reports whether the database carries all the fields in f.
+func (r *LookupResult) Supported(f Field) bool {
+	return r.Available&f == f
+}
+
+// Known reports whether the database has a value for field f for the
+// address: it carries f, the address was found and the value is not empty.
+// A zero latitude, longitude or elevation counts as unknown, see
+// Record.HasCoordinates.
+func (r *LookupResult) Known(f Field) bool {
+	if !r.Found || !r.Supported(f) {
+		return false
+	}
+	str, fl := r.Record.fieldPtr(f)
+	if str != nil {
+		return *str != "" && *str != "-"
+	}
+	return fl != nil && *fl != 0
+}
+
+// Lookup gets all fields like GetAll, along with the fields the database
+// carries and whether the address was found.
+func (db *DB) Lookup(ipaddress string) (*LookupResult, error) {
+	x, found, err := db.GetAllFound(ipaddress)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		x = &Record{}
+	}
+	return &LookupResult{Record: x, Available: db.Fields(), Found: found}, nil
+}
@@ -0,0 +1,20 @@
+//go:build linux
+
+package ip2location
+
+import "syscall"
+
+var adviceFlags = [...]int{
+	AdviceRandom:     syscall.MADV_RANDOM,
+	AdviceNormal:     syscall.MADV_NORMAL,
+	AdviceSequential: syscall.MADV_SEQUENTIAL,
+	AdviceWillNeed:   syscall.MADV_WILLNEED,
+}
+
+// pass the access pattern hint to the kernel
+func (m *mmapReader) advise(advice Advice) error {
+	if int(advice) >= len(adviceFlags) {
+		return syscall.EINVAL
+	}
+	return syscall.Madvise(m.data, adviceFlags[advice])
+}
@@ -0,0 +1,8 @@
+//go:build !linux
+
+package ip2location
+
+// madvise is not available through package syscall here
+func (m *mmapReader) advise(advice Advice) error {
+	return nil
+}
@@ -0,0 +1,22 @@
+package ip2location
+
+import "strconv"
+
+// ToMap returns the given fields of x keyed by their canonical names, as in
+// DB.FieldMap, for structured logging. Pass DB.Fields to get every field the
+// database carries, and only those. Every requested field is present, empty
+// strings included; floats are formatted with six decimals, like CSVRecord.
+func (x *Record) ToMap(fields Field) map[string]string {
+	m := make(map[string]string, len(fieldNames))
+	for _, e := range fieldNames {
+		if fields&e.field == 0 {
+			continue
+		}
+		if str, f := x.fieldPtr(e.field); str != nil {
+			m[e.name] = *str
+		} else {
+			m[e.name] = strconv.FormatFloat(float64(*f), 'f', 6, 32)
+		}
+	}
+	return m
+}
@@ -0,0 +1,33 @@
+package ip2location
+
+import (
+	"maps"
+	"testing"
+)
+
+func TestToMap(t *testing.T) {
+	db := testDB5.open(t)
+	x, err := db.GetAll("1.0.0.5")
+	if err != nil {
+		t.Fatal(err)
+	}
+	x.Region = "" // carried by DB5, so kept although empty
+	x.Isp = "ignored"
+
+	want := map[string]string{
+		"CountryShort": "AU",
+		"CountryLong":  "Australia",
+		"Region":       "",
+		"City":         "Brisbane",
+		"Latitude":     "-27.467939",
+		"Longitude":    "153.028091",
+	}
+	if got := x.ToMap(db.Fields()); !maps.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	want = map[string]string{"Isp": "ignored", "Elevation": "0.000000"}
+	if got := x.ToMap(FieldISP | FieldElevation); !maps.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
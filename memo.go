@@ -0,0 +1,19 @@
+package ip2location
+
+import "sync/atomic"
+
+// rowMemo remembers where the last address looked up by a single-field
+// getter was found, so that getting several fields of the same address one
+// call at a time searches the table once; see WithRowMemo. Shared by all
+// goroutines using the handle; a lookup of another address simply replaces
+// it.
+type rowMemo struct {
+	last atomic.Pointer[memoEntry]
+}
+
+type memoEntry struct {
+	ip        string
+	iptype    uint32
+	rowoffset uint32
+	found     bool
+}
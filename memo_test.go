@@ -0,0 +1,79 @@
+package ip2location
+
+import (
+	"sync/atomic"
+	"testing"
+)
+
+func TestMemo(t *testing.T) {
+	if testDB5.open(t).memo != nil {
+		t.Error("memo enabled without WithRowMemo")
+	}
+	for _, opts := range [][]Option{{WithRowMemo()}, {WithRowMemo(), WithPrivateIPShortcut(nil)}} {
+		db := testDB5.open(t, opts...)
+		for _, ip := range []string{"1.0.0.5", "1.0.0.5", "8.8.8.8", "10.1.2.3", "10.1.2.3", "1.0.0.5"} {
+			want, err := db.GetAll(ip)
+			if err != nil {
+				t.Fatal(err)
+			}
+			country, err := db.CountryShort(ip)
+			if err != nil || country != want.CountryShort {
+				t.Errorf("%s: CountryShort got %q, %v, want %q", ip, country, err, want.CountryShort)
+			}
+			city, err := db.City(ip)
+			if err != nil || city != want.City {
+				t.Errorf("%s: City got %q, %v, want %q", ip, city, err, want.City)
+			}
+		}
+	}
+}
+
+var memoCases = []struct {
+	name string
+	opts []Option
+}{{"memo", []Option{WithRowMemo()}}, {"nomemo", nil}}
+
+// several fields of one address, one getter at a time
+func BenchmarkMemo(b *testing.B) {
+	for _, c := range memoCases {
+		b.Run(c.name, func(b *testing.B) {
+			db := testDB5.openFile(b, c.opts...)
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				ip := benchIPs[i%len(benchIPs)]
+				if _, err := db.CountryShort(ip); err != nil {
+					b.Fatal(err)
+				}
+				if _, err := db.Region(ip); err != nil {
+					b.Fatal(err)
+				}
+				if _, err := db.City(ip); err != nil {
+					b.Fatal(err)
+				}
+				if _, err := db.Latitude(ip); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// one field of a different address per call, from all goroutines, where the
+// memo never hits
+func BenchmarkMemoParallel(b *testing.B) {
+	for _, c := range memoCases {
+		b.Run(c.name, func(b *testing.B) {
+			db := testDB5.openFile(b, c.opts...)
+			var next atomic.Uint64
+			b.ReportAllocs()
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					ip := benchIPs[next.Add(1)%uint64(len(benchIPs))]
+					if _, err := db.CountryShort(ip); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		})
+	}
+}
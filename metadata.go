@@ -0,0 +1,71 @@
+package ip2location
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"time"
+)
+
+// Metadata describes a database, as read from its header.
+type Metadata struct {
+	DatabaseType uint8     // 1-24 for IP2Location DB types, 1-12 for IP2Proxy PX types
+	Proxy        bool      // whether this is an IP2Proxy database
+	Columns      int       // columns per row
+	BuildDate    time.Time // zero if the stored date is not valid
+	IPv4Count    uint32    // rows in the IPv4 table
+	IPv6Count    uint32    // rows in the IPv6 table
+	HasIPv4      bool      // whether there is IPv4 data
+	HasIPv6      bool      // whether there is IPv6 data; false for IPv4-only products
+}
+
+// Metadata returns the header information of the database, such as whether
+// it covers both address families.
+func (db *DB) Metadata() Metadata {
+	built, _ := db.buildDate()
+	return Metadata{
+		DatabaseType: db.meta.databaseType,
+		Proxy:        db.meta.productCode == productIP2Proxy,
+		Columns:      int(db.meta.databesColumn),
+		BuildDate:    built,
+		IPv4Count:    db.meta.ipv4DatabaseCount,
+		IPv6Count:    db.meta.ipv6DatabaseCount,
+		HasIPv4:      db.meta.ipv4DatabaseCount > 0,
+		HasIPv6:      db.meta.ipv6DatabaseCount > 0,
+	}
+}
+
+// Fingerprint returns a short hex digest of the header: product, type,
+// build date, row counts and table addresses. It is the same for identical
+// headers, whatever the byte order or the way the database was opened, and
+// changes with every release of a database, so it is suited to keying caches
+// of lookup results.
+func (db *DB) Fingerprint() string {
+	m := db.meta
+	b := []byte{m.productCode, m.databaseType, m.databesColumn, m.databaseYear, m.databaseMonth, m.databaseDay}
+	for _, v := range []uint32{
+		m.ipv4DatabaseCount, m.ipv4DatabaseAddr,
+		m.ipv6DatabaseCount, m.ipv6DatabaseAddr,
+		m.ipv4IndexBaseAddr, m.ipv6IndexBaseAddr,
+	} {
+		b = binary.BigEndian.AppendUint32(b, v)
+	}
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:8])
+}
+
+// DatabaseType returns the database type from the header: 1 to 24 for the
+// IP2Location DB1-DB24 products, 1 to 12 for the IP2Proxy PX1-PX12 ones.
+func (db *DB) DatabaseType() uint8 {
+	return db.meta.databaseType
+}
+
+// DatabaseTypeName returns the product name of the database type, such as
+// "DB11" or "PX4". Fields lists the fields that type carries.
+func (db *DB) DatabaseTypeName() string {
+	if db.meta.productCode == productIP2Proxy {
+		return fmt.Sprintf("PX%d", db.meta.databaseType)
+	}
+	return fmt.Sprintf("DB%d", db.meta.databaseType)
+}
@@ -0,0 +1,18 @@
+//go:build !unix
+
+package ip2location
+
+import (
+	"errors"
+	"io"
+	"os"
+)
+
+type mmapReader struct {
+	io.ReaderAt
+	io.Closer
+}
+
+func mmapFile(f *os.File) (*mmapReader, error) {
+	return nil, errors.New("ip2location: mmap is not supported on this platform")
+}
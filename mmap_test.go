@@ -0,0 +1,37 @@
+//go:build unix
+
+package ip2location
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestMmapCloseDuringLookups(t *testing.T) {
+	db, err := Open(testDB5.write(t), WithMmap())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	start := make(chan struct{})
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			for range 1000 {
+				db.GetAll("8.8.8.8") // fails once the mapping is gone
+			}
+		}()
+	}
+	close(start)
+	if err := db.Close(); err != nil {
+		t.Fatal(err)
+	}
+	wg.Wait()
+
+	if _, err := db.GetAll("1.0.0.1"); err == nil {
+		t.Error("GetAll after Close succeeded")
+	}
+}
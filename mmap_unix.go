@@ -0,0 +1,60 @@
+//go:build unix
+
+package ip2location
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"syscall"
+)
+
+// mmapReader serves reads from a read-only memory mapping of a file. Reads
+// hold mu shared so that Close cannot unmap the data under them.
+type mmapReader struct {
+	mu   sync.RWMutex
+	data []byte
+}
+
+// map f into memory; f may be closed afterwards
+func mmapFile(f *os.File) (*mmapReader, error) {
+	fi, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	size := fi.Size()
+	if size <= 0 || int64(int(size)) != size {
+		return nil, fmt.Errorf("ip2location: cannot mmap %s of size %d", fi.Name(), size)
+	}
+	data, err := syscall.Mmap(int(f.Fd()), 0, int(size), syscall.PROT_READ, syscall.MAP_SHARED)
+	if err != nil {
+		return nil, err
+	}
+	return &mmapReader{data: data}, nil
+}
+
+func (m *mmapReader) ReadAt(p []byte, off int64) (int, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if m.data == nil {
+		return 0, os.ErrClosed
+	}
+	return readAtBytes(m.data, p, off)
+}
+
+func (m *mmapReader) Size() int64 {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return int64(len(m.data))
+}
+
+func (m *mmapReader) Close() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.data == nil {
+		return nil
+	}
+	data := m.data
+	m.data = nil
+	return syscall.Munmap(data)
+}
@@ -0,0 +1,81 @@
+package ip2location
+
+import "errors"
+
+// MergePolicy decides which value a MultiDB keeps when several databases
+// populate the same field.
+type MergePolicy uint8
+
+const (
+	MergeFirstWins MergePolicy = iota // keep the value from the earliest database
+	MergeLastWins                     // keep the value from the latest database
+)
+
+// MultiDB looks up addresses in several databases, such as separately
+// licensed ISP and usage type products, and merges the results.
+type MultiDB struct {
+	dbs    []*DB
+	policy MergePolicy
+}
+
+// NewMultiDB returns a MultiDB over dbs, in order of precedence for the
+// given policy. The MultiDB takes ownership of the handles.
+func NewMultiDB(policy MergePolicy, dbs ...*DB) *MultiDB {
+	return &MultiDB{dbs: dbs, policy: policy}
+}
+
+// GetAll gets all fields from every database and merges the non-empty ones
+// into a single Record. Strings count as empty when "" or "-", the
+// placeholder databases store for missing data, and floats when 0; "-" is
+// kept only when no database has a value. The country code and name are
+// taken together from one database, so they always agree. The first error
+// from any database is returned.
+func (m *MultiDB) GetAll(ipaddress string) (*Record, error) {
+	x := &Record{}
+	for _, db := range m.dbs {
+		y, err := db.GetAll(ipaddress)
+		if err != nil {
+			return nil, err
+		}
+		if m.take(x.CountryShort, y.CountryShort) {
+			x.CountryShort, x.CountryLong = y.CountryShort, y.CountryLong
+		}
+		for _, e := range fieldNames {
+			if e.field&(FieldCountryShort|FieldCountryLong) != 0 {
+				continue
+			}
+			dstStr, dstF := x.fieldPtr(e.field)
+			srcStr, srcF := y.fieldPtr(e.field)
+			if dstStr != nil {
+				if m.take(*dstStr, *srcStr) {
+					*dstStr = *srcStr
+				}
+			} else if *srcF != 0 && (*dstF == 0 || m.policy == MergeLastWins) {
+				*dstF = *srcF
+			}
+		}
+	}
+	return x, nil
+}
+
+// reports whether the merged value dst should be replaced by src
+func (m *MultiDB) take(dst, src string) bool {
+	switch {
+	case src == "":
+		return false
+	case src == "-":
+		return dst == "" // better than nothing
+	}
+	return dst == "" || dst == "-" || m.policy == MergeLastWins
+}
+
+// Close closes all databases.
+func (m *MultiDB) Close() error {
+	var errs []error
+	for _, db := range m.dbs {
+		if err := db.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,64 @@
+package ip2location
+
+import "testing"
+
+func TestMultiDBGetAll(t *testing.T) {
+	us := Record{CountryShort: "US", CountryLong: "United States of America"}
+	au := Record{CountryShort: "AU", CountryLong: "Australia"}
+	none := Record{CountryShort: "-", CountryLong: "-"}
+	with := func(x Record, isp string) Record {
+		x.Isp = isp
+		return x
+	}
+
+	for _, tt := range []struct {
+		name   string
+		policy MergePolicy
+		a, b   Record
+		want   Record
+	}{
+		{"first wins", MergeFirstWins, with(us, "Google"), with(au, "Telstra"), with(us, "Google")},
+		{"last wins", MergeLastWins, with(us, "Google"), with(au, "Telstra"), with(au, "Telstra")},
+		{"first wins over -", MergeFirstWins, with(us, "-"), with(au, "Telstra"), with(us, "Telstra")},
+		{"first wins fills -", MergeFirstWins, with(none, "Google"), with(au, "-"), with(au, "Google")},
+		{"last wins keeps over -", MergeLastWins, with(us, "Google"), with(none, "-"), with(us, "Google")},
+		{"all -", MergeLastWins, with(none, "-"), with(none, "-"), with(none, "-")},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			a := testDB{Type: 2, V4: []testRange{{From: "0.0.0.0", Rec: tt.a}}}.open(t)
+			b := testDB{Type: 2, V4: []testRange{{From: "0.0.0.0", Rec: tt.b}}}.open(t)
+			m := NewMultiDB(tt.policy, a, b)
+
+			x, err := m.GetAll("8.8.8.8")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if *x != tt.want {
+				t.Errorf("GetAll = %+v, want %+v", *x, tt.want)
+			}
+		})
+	}
+}
+
+// the code and name come from one database even when a later one only has
+// the code
+func TestMultiDBCountryPair(t *testing.T) {
+	a := testDB{Type: 1, V4: []testRange{{From: "0.0.0.0", Rec: Record{CountryShort: "US", CountryLong: "United States of America"}}}}.open(t)
+	b := testDB{Type: 1, V4: []testRange{{From: "0.0.0.0", Rec: Record{CountryShort: "AU", CountryLong: "-"}}}}.open(t)
+
+	for _, tt := range []struct {
+		policy      MergePolicy
+		short, long string
+	}{
+		{MergeFirstWins, "US", "United States of America"},
+		{MergeLastWins, "AU", "-"},
+	} {
+		x, err := NewMultiDB(tt.policy, a, b).GetAll("8.8.8.8")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if x.CountryShort != tt.short || x.CountryLong != tt.long {
+			t.Errorf("policy %d: country = %q, %q, want %q, %q", tt.policy, x.CountryShort, x.CountryLong, tt.short, tt.long)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package ip2location
+
+// NetSpeedType is the parsed form of Record.NetSpeed.
+type NetSpeedType uint8
+
+const (
+	NetSpeedUnknown   NetSpeedType = iota // empty or unrecognized code
+	NetSpeedDial                          // "DIAL": dial-up
+	NetSpeedDSL                           // "DSL": broadband, cable, fiber
+	NetSpeedCompany                       // "COMP": company/T1
+	NetSpeedT1                            // "T1": dedicated T1 line
+	NetSpeedSatellite                     // "SAT": satellite
+)
+
+var netSpeedCodes = map[string]NetSpeedType{
+	"DIAL": NetSpeedDial,
+	"DSL":  NetSpeedDSL,
+	"COMP": NetSpeedCompany,
+	"T1":   NetSpeedT1,
+	"SAT":  NetSpeedSatellite,
+}
+
+// ParseNetSpeed maps a raw net speed code to its NetSpeedType.
+// Unrecognized codes map to NetSpeedUnknown.
+func ParseNetSpeed(code string) NetSpeedType {
+	return netSpeedCodes[code]
+}
+
+// String returns the raw IP2Location code for t, or "" if unknown.
+func (t NetSpeedType) String() string {
+	for code, v := range netSpeedCodes {
+		if v == t {
+			return code
+		}
+	}
+	return ""
+}
+
+// NetSpeedType returns the parsed net speed. The raw code is kept in NetSpeed
+// so that codes unknown to this package are not lost.
+func (x *Record) NetSpeedType() NetSpeedType {
+	return ParseNetSpeed(x.NetSpeed)
+}
@@ -0,0 +1,31 @@
+package ip2location
+
+// NetworkInfo is the subset of a Record used for traffic scoring.
+type NetworkInfo struct {
+	Isp         string
+	Domain      string
+	NetSpeed    NetSpeedType
+	UsageType   UsageType
+	Mcc         string
+	Mnc         string
+	MobileBrand string
+}
+
+// GetNetworkInfo gets ISP, domain, net speed, usage type and mobile carrier
+// fields with a single search. Fields the database does not carry are empty
+// or unknown.
+func (db *DB) GetNetworkInfo(ipaddress string) (*NetworkInfo, error) {
+	x, err := db.query(ipaddress, FieldISP|FieldDomain|FieldNetSpeed|FieldUsageType|FieldMCC|FieldMNC|FieldMobileBrand)
+	if err != nil {
+		return nil, err
+	}
+	return &NetworkInfo{
+		Isp:         x.Isp,
+		Domain:      x.Domain,
+		NetSpeed:    x.NetSpeedType(),
+		UsageType:   x.UsageTypeParsed(),
+		Mcc:         x.Mcc,
+		Mnc:         x.Mnc,
+		MobileBrand: x.MobileBrand,
+	}, nil
+}
@@ -0,0 +1,16 @@
+package ip2location
+
+import "time"
+
+// observer holds the callback set by WithObserver
+type observer struct {
+	threshold time.Duration
+	fn        func(ip string, dur time.Duration, err error)
+}
+
+// report the lookup of ip started at start if it failed or was slow
+func (o *observer) observe(ip string, start time.Time, err error) {
+	if dur := time.Since(start); err != nil || dur >= o.threshold {
+		o.fn(ip, dur, err)
+	}
+}
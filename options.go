@@ -0,0 +1,237 @@
+package ip2location
+
+import (
+	"encoding/binary"
+	"time"
+)
+
+// Option configures optional behavior of a DB at Open time.
+type Option func(*options)
+
+type options struct {
+	mmap             bool
+	preload          bool
+	indexCache       bool
+	stringCacheSize  int
+	rowMemo          bool
+	strictValidation bool
+	ipv4Embedded     bool
+	readTimeout      time.Duration
+	noIndex          bool
+	byteOrder        binary.ByteOrder
+	stats            bool
+	strDecoder       func([]byte) string
+	advice           Advice
+	shared           bool
+	privateRecord    *Record
+	observer         *observer
+	maxStrLen        int
+	centuryBase      int
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// WithMmap memory-maps the database file instead of reading it with ReadAt.
+// It is only supported on Unix platforms; elsewhere Open returns an error.
+func WithMmap() Option {
+	return func(o *options) {
+		o.mmap = true
+	}
+}
+
+// WithPreload reads the whole database file into memory at Open and serves
+// all lookups from it, trading memory (the size of the database) for the
+// absence of syscalls and page faults. It takes precedence over WithMmap.
+func WithPreload() Option {
+	return func(o *options) {
+		o.preload = true
+	}
+}
+
+// WithIndexCache loads the IPv4 and IPv6 index tables into memory at Open,
+// saving two reads per lookup at the cost of about 1MB of memory.
+func WithIndexCache() Option {
+	return func(o *options) {
+		o.indexCache = true
+	}
+}
+
+// WithoutIndex ignores the index tables and binary-searches the whole IPv4 or
+// IPv6 table on every lookup. It is slower, but works around databases whose
+// index bounds are inconsistent with their data, and helps confirm whether
+// the index is at fault when results look wrong. WithIndexCache has no effect
+// with it.
+func WithoutIndex() Option {
+	return func(o *options) {
+		o.noIndex = true
+	}
+}
+
+// WithStringCache enables a bounded LRU cache of decoded strings keyed by
+// their file offset. Popular values (countries, large ISPs) are then served
+// from memory instead of being re-read on every lookup. A size <= 0 disables
+// the cache.
+func WithStringCache(size int) Option {
+	return func(o *options) {
+		o.stringCacheSize = size
+	}
+}
+
+// WithRowMemo makes the single-field getters (CountryShort, City, ...)
+// remember where the last address they looked up was found, so that getting
+// several fields of one address a call at a time searches the table once. It
+// helps a single goroutine doing so; concurrent lookups of other addresses
+// keep replacing the remembered row, and then only add an allocation per
+// call.
+func WithRowMemo() Option {
+	return func(o *options) {
+		o.rowMemo = true
+	}
+}
+
+// WithStrictValidation makes Open reject databases whose size cannot be
+// determined, whose index tables do not fit in the file, or whose column
+// count does not match the database type.
+func WithStrictValidation() Option {
+	return func(o *options) {
+		o.strictValidation = true
+	}
+}
+
+// WithIPv4Embedded looks up IPv6 addresses that embed an IPv4 address as that
+// IPv4 address: NAT64 (64:ff9b::1.2.3.4) and 6to4 (2002:0102:0304::).
+// IPv4-mapped addresses (::ffff:1.2.3.4) are always treated as IPv4.
+func WithIPv4Embedded() Option {
+	return func(o *options) {
+		o.ipv4Embedded = true
+	}
+}
+
+// WithReadTimeout fails any single read from the database file that takes
+// longer than d with an error wrapping os.ErrDeadlineExceeded, instead of
+// blocking the lookup indefinitely. It is meant for files on network storage.
+// Each read then runs in its own goroutine; the stalled read itself is not
+// cancelled. It has no effect, and no cost, with WithMmap, WithPreload or
+// OpenBytes, whose reads never block on I/O. A d <= 0 disables the timeout.
+func WithReadTimeout(d time.Duration) Option {
+	return func(o *options) {
+		o.readTimeout = d
+	}
+}
+
+// WithByteOrder reads the database with the given byte order instead of
+// detecting it. By default the header is read as little-endian, as in all
+// IP2Location databases, and as big-endian only if it is not self-consistent
+// that way.
+func WithByteOrder(order binary.ByteOrder) Option {
+	return func(o *options) {
+		o.byteOrder = order
+	}
+}
+
+// WithStats counts lookups, misses, invalid addresses and reads, as reported
+// by DB.Stats. The counters are atomic and safe under concurrent lookups.
+func WithStats() Option {
+	return func(o *options) {
+		o.stats = true
+	}
+}
+
+// WithStringDecoder converts the raw bytes of every string read from the
+// database with decode, e.g. DecodeLatin1 for databases whose names are not
+// UTF-8. decode must not retain b.
+func WithStringDecoder(decode func(b []byte) string) Option {
+	return func(o *options) {
+		o.strDecoder = decode
+	}
+}
+
+// Advice is an access pattern hint for a memory-mapped database.
+type Advice uint8
+
+const (
+	AdviceRandom     Advice = iota // MADV_RANDOM: no readahead; the default
+	AdviceNormal                   // MADV_NORMAL: the kernel's default readahead
+	AdviceSequential               // MADV_SEQUENTIAL: aggressive readahead
+	AdviceWillNeed                 // MADV_WILLNEED: start reading the whole file in
+)
+
+// WithMadvise sets the access pattern hint given to the kernel for a database
+// opened with WithMmap. Without it, mappings are advised AdviceRandom, since
+// lookups touch a few scattered pages and readahead mostly fills the page
+// cache with data that is never read. This matters most when the database is
+// larger than the memory available for the page cache. The hint is only
+// applied on Linux; elsewhere it is silently ignored.
+func WithMadvise(advice Advice) Option {
+	return func(o *options) {
+		o.advice = advice
+	}
+}
+
+// WithShared makes Open return a Clone of an already open handle on the same
+// unchanged file, opened with the same options, instead of reading and
+// parsing it again. The file is released when the last of these handles is
+// closed. Handles opened without WithShared are never shared, which also
+// keeps separate file descriptors, e.g. in a Pool. WithStringDecoder,
+// WithObserver and a WithByteOrder other than binary.LittleEndian or
+// binary.BigEndian disable sharing, since they cannot be compared.
+func WithShared() Option {
+	return func(o *options) {
+		o.shared = true
+	}
+}
+
+// WithPrivateIPShortcut answers lookups of private and reserved addresses
+// without searching the database, see IsReservedIP. They get the fields of x
+// instead, or a Record with CountryShort "ZZ", the user-assigned code for an
+// unknown country, if x is nil. This applies to every lookup of a single
+// address, such as GetAll, Query, GetAllPartial, Contains or GetAllByUint32;
+// GetAllRange reports the reserved block as the range. RawRow and
+// ColumnPointers, which return stored rows, report ErrIPNotFound instead.
+// Scans of the table, such as Iterate, LookupPrefix and GetAllInRange, are
+// not affected.
+func WithPrivateIPShortcut(x *Record) Option {
+	return func(o *options) {
+		if x == nil {
+			x = &Record{CountryShort: "ZZ"}
+		}
+		r := *x
+		o.privateRecord = &r
+	}
+}
+
+// WithObserver calls fn after every lookup that fails or takes at least
+// threshold, with the address, the time taken and the error, if any. A zero
+// threshold observes every lookup. It applies to GetAll, Query, QueryInto
+// and the Get methods and single-value getters built on them. fn is called
+// synchronously, without any lock held, and must be safe for concurrent use.
+func WithObserver(threshold time.Duration, fn func(ip string, dur time.Duration, err error)) Option {
+	return func(o *options) {
+		o.observer = &observer{threshold: threshold, fn: fn}
+	}
+}
+
+// WithMaxStringLen makes lookups fail with ErrCorruptDatabase when a string
+// is longer than n bytes, which with a sensible n catches pointers into the
+// middle of unrelated data. Strings overlapping the header, tables or
+// indexes are rejected regardless.
+func WithMaxStringLen(n int) Option {
+	return func(o *options) {
+		o.maxStrLen = n
+	}
+}
+
+// WithCenturyBase sets the year the two-digit build year of the header is
+// added to, 2000 by default, e.g. 1900 for archived files built in the
+// 1990s. It affects Metadata().BuildDate and Age.
+func WithCenturyBase(year int) Option {
+	return func(o *options) {
+		o.centuryBase = year
+	}
+}
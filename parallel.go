@@ -0,0 +1,62 @@
+package ip2location
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// GetAllParallel gets all fields for each address, spreading the lookups
+// across workers goroutines, or GOMAXPROCS if workers is zero or negative.
+// The results are aligned with ips: records[i] and errs[i] are what
+// GetAll(ips[i]) returns. An error only concerns its own address; see
+// GetAllParallelJoined for a single error instead.
+func (db *DB) GetAllParallel(ips []string, workers int) ([]*Record, []error) {
+	records := make([]*Record, len(ips))
+	errs := make([]error, len(ips))
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	if workers > len(ips) {
+		workers = len(ips)
+	}
+
+	// workers take the next unresolved address, so a slow lookup does not
+	// hold back a fixed share of the input
+	var next int64 = -1
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+			for {
+				i := int(atomic.AddInt64(&next, 1))
+				if i >= len(ips) {
+					return
+				}
+				records[i], errs[i] = db.GetAll(ips[i])
+			}
+		}()
+	}
+	wg.Wait()
+	return records, errs
+}
+
+// GetAllParallelJoined is like GetAllParallel but combines the errors into
+// one with errors.Join, each prefixed with the index and address it belongs
+// to, for callers that would rather use errors.Is than scan a slice. The
+// records of the addresses that failed are nil. As with GetAllParallel, no
+// error is fatal: invalid addresses and read errors alike only affect their
+// own element, and every address is looked up.
+func (db *DB) GetAllParallelJoined(ips []string, workers int) ([]*Record, error) {
+	records, errs := db.GetAllParallel(ips, workers)
+	var joined []error
+	for i, err := range errs {
+		if err != nil {
+			joined = append(joined, fmt.Errorf("%d: %q: %w", i, ips[i], err))
+		}
+	}
+	return records, errors.Join(joined...)
+}
@@ -0,0 +1,42 @@
+package ip2location
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestGetAllParallel(t *testing.T) {
+	db := testDB5.open(t)
+	ips := append([]string{"bogus"}, benchIPs...)
+	for _, workers := range []int{0, 1, 3, 100} {
+		records, errs := db.GetAllParallel(ips, workers)
+		for i, ip := range ips {
+			want, wantErr := db.GetAll(ip)
+			if errs[i] != wantErr || (want == nil) != (records[i] == nil) || (want != nil && *records[i] != *want) {
+				t.Errorf("workers %d, %s: got %v, %v, want %v, %v", workers, ip, records[i], errs[i], want, wantErr)
+			}
+		}
+	}
+	if _, err := db.GetAllParallelJoined(ips, 2); !errors.Is(err, ErrInvalidAddress) {
+		t.Errorf("joined error %v does not wrap ErrInvalidAddress", err)
+	}
+}
+
+func BenchmarkParallelLookup(b *testing.B) {
+	db := testDB5.openFile(b)
+	ips := make([]string, 1024)
+	for i := range ips {
+		ips[i] = benchIPs[i%len(benchIPs)]
+	}
+	for _, workers := range []int{1, 2, 4, 8, 16} {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := db.GetAllParallelJoined(ips, workers); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package ip2location
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ParseRecordString parses the output of Record.String or
+// Record.StringCompact back into a Record. Missing lines leave their fields
+// empty and blank lines are ignored; unknown labels are an error. Floats
+// logged by versions whose String appended "ile" to them are accepted too.
+func ParseRecordString(s string) (*Record, error) {
+	x := &Record{}
+	for n, line := range strings.Split(s, "\n") {
+		if line == "" {
+			continue
+		}
+		label, value, ok := strings.Cut(line, ":")
+		if !ok {
+			return nil, fmt.Errorf("ip2location: line %d: missing label: %q", n+1, line)
+		}
+		value = strings.TrimPrefix(value, " ")
+
+		str, f := x.fieldPtr(fieldByLabel(label))
+		switch {
+		case str != nil:
+			*str = value
+		case f != nil:
+			v, err := strconv.ParseFloat(strings.TrimSuffix(value, "ile"), 32)
+			if err != nil {
+				return nil, fmt.Errorf("ip2location: line %d: %s: %w", n+1, label, err)
+			}
+			*f = float32(v)
+		default:
+			return nil, fmt.Errorf("ip2location: line %d: unknown label %q", n+1, label)
+		}
+	}
+	return x, nil
+}
+
+// the Field labelled label in Record.String, or 0
+func fieldByLabel(label string) Field {
+	for _, e := range fieldNames {
+		if e.label == label {
+			return e.field
+		}
+	}
+	return 0
+}
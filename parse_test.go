@@ -0,0 +1,59 @@
+package ip2location
+
+import "testing"
+
+func TestParseRecordStringRoundTrip(t *testing.T) {
+	full := Record{
+		CountryShort: "US", CountryLong: "United States of America", Region: "California",
+		City: "Mountain View", Isp: "Google LLC", Latitude: 37.405991, Longitude: -122.078514,
+		Domain: "google.com", Zipcode: "94043", TimeZone: "-07:00", NetSpeed: "T1",
+		IddCode: "1", Areacode: "650", WeatherStationCode: "USCA0746", WeatherStationName: "Mountain View",
+		Mcc: "-", Mnc: "-", MobileBrand: "-", Elevation: -12.5, UsageType: "DCH",
+		ProxyType: "DCH", Threat: "-", Provider: "Google",
+	}
+	for _, c := range []struct {
+		name string
+		x    Record
+	}{
+		{"empty", Record{}},
+		{"full", full},
+		{"country only", Record{CountryShort: "AU", CountryLong: "Australia"}},
+		{"floats only", Record{Latitude: -33.86882, Longitude: 151.20929, Elevation: 58}},
+		{"proxy only", Record{ProxyType: "VPN"}},
+		{"colons and spaces", Record{City: " Ho Chi Minh: City ", TimeZone: "+07:00"}},
+	} {
+		for format, s := range map[string]string{"String": c.x.String(), "StringCompact": c.x.StringCompact()} {
+			got, err := ParseRecordString(s)
+			if err != nil {
+				t.Errorf("%s, %s: %v", c.name, format, err)
+				continue
+			}
+			for _, e := range fieldNames {
+				wantStr, wantF := c.x.fieldPtr(e.field)
+				gotStr, gotF := got.fieldPtr(e.field)
+				if wantStr != nil && *gotStr != *wantStr {
+					t.Errorf("%s, %s: %s = %q, want %q", c.name, format, e.name, *gotStr, *wantStr)
+				}
+				if wantF != nil && *gotF != *wantF {
+					t.Errorf("%s, %s: %s = %v, want %v", c.name, format, e.name, *gotF, *wantF)
+				}
+			}
+		}
+	}
+}
+
+func TestParseRecordStringErrors(t *testing.T) {
+	for _, s := range []string{
+		"country_short US",
+		"colour: blue",
+		"latitude: north",
+	} {
+		if _, err := ParseRecordString(s); err == nil {
+			t.Errorf("%q: no error", s)
+		}
+	}
+	x, err := ParseRecordString("latitude: 1.500000ile\n\ncity: Perth\n")
+	if err != nil || x.Latitude != 1.5 || x.City != "Perth" {
+		t.Errorf("got %+v, %v", x, err)
+	}
+}
@@ -0,0 +1,48 @@
+package ip2location
+
+import (
+	"errors"
+	"fmt"
+)
+
+// GetAllPartial is like GetAll, but a read error in one field does not
+// discard the others: the Record is returned with every field that could be
+// read, along with an errors.Join of the per-field errors, each prefixed with
+// the field name. The row is read once; malformed addresses, search errors
+// and a row that cannot be read still return a nil Record.
+func (db *DB) GetAllPartial(ipaddress string) (*Record, error) {
+	iptype, ipno, ipindex := db.checkIP(ipaddress)
+	if iptype == 0 {
+		return nil, ErrInvalidAddress
+	}
+
+	x := &Record{}
+	rowoffset, found, err := db.find(iptype, ipno, ipindex)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return x, nil
+	}
+	if rowoffset == privateRow {
+		db.fillPrivate(x, FieldAll)
+		return x, nil
+	}
+
+	row, buf, err := db.readRow(iptype, rowoffset)
+	if buf != nil {
+		defer bufRowPool.Put(buf)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// the row is read once, each field decoded from it on its own
+	var errs []error
+	for _, e := range fieldNames {
+		if err := db.decodeRow(x, row, e.field); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", e.name, err))
+		}
+	}
+	return x, errors.Join(errs...)
+}
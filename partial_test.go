@@ -0,0 +1,66 @@
+package ip2location
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// countingReader counts the reads from r
+type countingReader struct {
+	r     *bytes.Reader
+	reads atomic.Int64
+}
+
+func (c *countingReader) ReadAt(p []byte, off int64) (int, error) {
+	c.reads.Add(1)
+	return c.r.ReadAt(p, off)
+}
+
+func (c *countingReader) Size() int64 { return c.r.Size() }
+
+func TestGetAllPartial(t *testing.T) {
+	data := testDB5.build()
+	// point the city of the row of 1.0.0.0 past the end of the file
+	probe := &DB{meta: &dbMeta{}}
+	probe.setColumns(5)
+	row := 64 + 4*int(typeColumns(5)) // the second row
+	binary.LittleEndian.PutUint32(data[row+int(probe.cityPositionOffset):], 0xfffffff0)
+
+	cr := &countingReader{r: bytes.NewReader(data)}
+	db, err := OpenReader(cr, WithoutIndex())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	x, err := db.GetAllPartial("1.0.0.5")
+	if !errors.Is(err, ErrCorruptDatabase) || !strings.HasPrefix(err.Error(), "City: ") {
+		t.Errorf("got error %v, want a City error wrapping ErrCorruptDatabase", err)
+	}
+	want := testRangesV4[1].Rec
+	want.City = ""
+	if x == nil || *x != want {
+		t.Errorf("got %+v, want %+v", x, want)
+	}
+
+	// the row is read once: a partial lookup reads no more than a full one
+	cr.reads.Store(0)
+	if _, err := db.GetAllPartial("1.0.1.5"); err != nil {
+		t.Fatal(err)
+	}
+	partial := cr.reads.Load()
+	cr.reads.Store(0)
+	if _, err := db.GetAll("1.0.1.5"); err != nil {
+		t.Fatal(err)
+	}
+	if full := cr.reads.Load(); partial != full {
+		t.Errorf("GetAllPartial made %d reads, GetAll %d", partial, full)
+	}
+
+	if x, err := db.GetAllPartial("bogus"); x != nil || err != ErrInvalidAddress {
+		t.Errorf("malformed address: got %v, %v", x, err)
+	}
+}
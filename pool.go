@@ -0,0 +1,67 @@
+package ip2location
+
+import (
+	"errors"
+	"sync/atomic"
+)
+
+// Pool holds several handles to the same database file, each with its own
+// file descriptor, and hands them out round-robin.
+//
+// A single *DB is already safe for concurrent use, since reads go through
+// ReadAt. A Pool only helps when lookups are bound by contention on one file
+// descriptor, i.e. with very high query rates on plain file-backed handles.
+// It gives no benefit with WithMmap or in-memory databases.
+type Pool struct {
+	dbs    []*DB
+	next   uint32
+	closed uint32 // set once by Close
+}
+
+// NewPool opens the database at dbPath n times with the given options.
+// WithShared is rejected, since it would make the n handles share a single
+// file descriptor.
+func NewPool(dbPath string, n int, opts ...Option) (*Pool, error) {
+	if n < 1 {
+		return nil, errors.New("ip2location: pool size must be at least 1")
+	}
+	if newOptions(opts).shared {
+		return nil, errors.New("ip2location: pool opened WithShared")
+	}
+
+	p := &Pool{dbs: make([]*DB, 0, n)}
+	for i := 0; i < n; i++ {
+		db, err := Open(dbPath, opts...)
+		if err != nil {
+			p.Close()
+			return nil, err
+		}
+		p.dbs = append(p.dbs, db)
+	}
+	return p, nil
+}
+
+// Get returns the next handle. The handle must not be closed by the caller.
+// Calling Get after Close is a programming error and panics.
+func (p *Pool) Get() *DB {
+	if atomic.LoadUint32(&p.closed) != 0 {
+		panic("ip2location: Get on a closed Pool")
+	}
+	i := atomic.AddUint32(&p.next, 1)
+	return p.dbs[i%uint32(len(p.dbs))]
+}
+
+// Close closes all handles in the pool. Calling Close more than once is a
+// no-op.
+func (p *Pool) Close() error {
+	if !atomic.CompareAndSwapUint32(&p.closed, 0, 1) {
+		return nil
+	}
+	var errs []error
+	for _, db := range p.dbs {
+		if err := db.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,77 @@
+package ip2location
+
+import "testing"
+
+func TestPool(t *testing.T) {
+	path := testDB5.write(t)
+	if _, err := NewPool(path, 0); err == nil {
+		t.Error("NewPool with size 0 succeeded")
+	}
+	if _, err := NewPool(path, 2, WithShared()); err == nil {
+		t.Error("NewPool WithShared succeeded")
+	}
+	p, err := NewPool(path, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	seen := map[*DB]bool{}
+	for range 6 {
+		db := p.Get()
+		seen[db] = true
+		x, err := db.GetAll("8.8.8.8")
+		if err != nil || x.CountryShort != "US" {
+			t.Errorf("GetAll: %v %+v", err, x)
+		}
+	}
+	if len(seen) != 3 {
+		t.Errorf("Get handed out %d handles, want 3", len(seen))
+	}
+	if err := p.Close(); err != nil {
+		t.Error(err)
+	}
+	if err := p.Close(); err != nil {
+		t.Errorf("second Close: %v", err)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Get after Close did not panic")
+		}
+	}()
+	p.Get()
+}
+
+// single file descriptor against a pool of them, both under parallel load
+func BenchmarkGetAllPool(b *testing.B) {
+	path := testDB5.write(b)
+	b.Run("single", func(b *testing.B) {
+		db, err := Open(path)
+		if err != nil {
+			b.Fatal(err)
+		}
+		defer db.Close()
+		benchmarkParallel(b, func() *DB { return db })
+	})
+	b.Run("pool", func(b *testing.B) {
+		p, err := NewPool(path, 4)
+		if err != nil {
+			b.Fatal(err)
+		}
+		defer p.Close()
+		benchmarkParallel(b, p.Get)
+	})
+}
+
+func benchmarkParallel(b *testing.B, get func() *DB) {
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			if _, err := get().GetAll(benchIPs[i%len(benchIPs)]); err != nil {
+				b.Error(err)
+				return
+			}
+			i++
+		}
+	})
+}
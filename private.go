@@ -0,0 +1,83 @@
+package ip2location
+
+import (
+	"math/big"
+	"net/netip"
+)
+
+// the private (RFC 1918, RFC 4193), loopback, link-local, multicast and
+// unspecified blocks
+var reservedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/32"),
+	netip.MustParsePrefix("10.0.0.0/8"),
+	netip.MustParsePrefix("127.0.0.0/8"),
+	netip.MustParsePrefix("169.254.0.0/16"),
+	netip.MustParsePrefix("172.16.0.0/12"),
+	netip.MustParsePrefix("192.168.0.0/16"),
+	netip.MustParsePrefix("224.0.0.0/4"),
+	netip.MustParsePrefix("::/128"),
+	netip.MustParsePrefix("::1/128"),
+	netip.MustParsePrefix("fc00::/7"),
+	netip.MustParsePrefix("fe80::/10"),
+	netip.MustParsePrefix("ff00::/8"),
+}
+
+// IsReservedIP reports whether ip is a private (RFC 1918, RFC 4193),
+// loopback, link-local, multicast or unspecified address, which IP2Location
+// databases have no useful data for. IPv4-mapped IPv6 addresses are checked
+// as IPv4. Malformed addresses are not reserved.
+func IsReservedIP(ip string) bool {
+	a, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+	_, ok := reservedPrefix(a.Unmap().WithZone(""))
+	return ok
+}
+
+// the reserved block containing a
+func reservedPrefix(a netip.Addr) (netip.Prefix, bool) {
+	for _, p := range reservedPrefixes {
+		if p.Contains(a) {
+			return p, true
+		}
+	}
+	return netip.Prefix{}, false
+}
+
+// the reserved block containing the IP number ipno of the table iptype
+func reservedBlock(iptype uint32, ipno *big.Int) (netip.Prefix, bool) {
+	return reservedPrefix(bigToAddr(iptype, ipno))
+}
+
+// the [ipfrom, ipto) bounds of p, as search returns them
+func prefixBounds(p netip.Prefix) (ipfrom, ipto *big.Int) {
+	ipfrom = new(big.Int).SetBytes(p.Addr().AsSlice())
+	ipto = new(big.Int).Lsh(big.NewInt(1), uint(p.Addr().BitLen()-p.Bits()))
+	ipto.Add(ipto, ipfrom)
+	// the top address is matched by the last range, see rangeAddrs
+	maxip := maxIpv4Range
+	if p.Addr().Is6() {
+		maxip = maxIpv6Range
+	}
+	if ipto.Cmp(maxip) > 0 {
+		ipto.Set(maxip)
+	}
+	return ipfrom, ipto
+}
+
+// fill the requested fields of x from the WithPrivateIPShortcut record
+func (db *DB) fillPrivate(x *Record, mode Field) {
+	for _, e := range fieldNames {
+		if mode&e.field == 0 {
+			continue
+		}
+		str, f := x.fieldPtr(e.field)
+		pStr, pF := db.privateRecord.fieldPtr(e.field)
+		if str != nil {
+			*str = *pStr
+		} else {
+			*f = *pF
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package ip2location
+
+import (
+	"errors"
+	"math/big"
+	"net/netip"
+	"testing"
+)
+
+func TestIsReservedIP(t *testing.T) {
+	for ip, want := range map[string]bool{
+		"10.1.2.3":        true,
+		"172.31.255.255":  true,
+		"172.32.0.0":      false,
+		"192.168.0.1":     true,
+		"127.0.0.1":       true,
+		"169.254.1.1":     true,
+		"224.0.0.251":     true,
+		"239.255.255.255": true,
+		"240.0.0.1":       false,
+		"0.0.0.0":         true,
+		"0.0.0.1":         false,
+		"::ffff:10.0.0.1": true,
+		"::":              true,
+		"::1":             true,
+		"fd00::1":         true,
+		"fe80::1%eth0":    true,
+		"ff02::1":         true,
+		"8.8.8.8":         false,
+		"2001:4860::1":    false,
+		"bogus":           false,
+	} {
+		if got := IsReservedIP(ip); got != want {
+			t.Errorf("%s: got %v, want %v", ip, got, want)
+		}
+	}
+}
+
+// every lookup of a single address gives the shortcut record
+func TestPrivateIPShortcut(t *testing.T) {
+	private := Record{CountryShort: "ZZ", CountryLong: "Private", City: "LAN"}
+	db := testDB5.open(t, WithPrivateIPShortcut(&private))
+	plain := testDB5.open(t)
+
+	for _, ip := range []string{"10.1.2.3", "::ffff:192.168.1.1", "::1", "fd12::1"} {
+		lookups := map[string]func() (*Record, error){
+			"GetAll": func() (*Record, error) { return db.GetAll(ip) },
+			"Query":  func() (*Record, error) { return db.Query(ip, FieldAll) },
+			"QueryInto": func() (*Record, error) {
+				var x Record
+				return &x, db.QueryInto(ip, FieldAll, &x)
+			},
+			"GetAllFound": func() (*Record, error) {
+				x, found, err := db.GetAllFound(ip)
+				if !found {
+					return nil, errors.New("not found")
+				}
+				return x, err
+			},
+			"GetAllOrDefault": func() (*Record, error) { return db.GetAllOrDefault(ip, nil) },
+			"GetAllPartial":   func() (*Record, error) { return db.GetAllPartial(ip) },
+			"GetAllNearest": func() (*Record, error) {
+				x, approximate, err := db.GetAllNearest(ip)
+				if approximate {
+					return nil, errors.New("approximate")
+				}
+				return x, err
+			},
+			"GetAllRange": func() (*Record, error) {
+				r, err := db.GetAllRange(ip)
+				if err != nil {
+					return nil, err
+				}
+				return r.Record, nil
+			},
+			"getters": func() (*Record, error) {
+				country, err := db.CountryShort(ip)
+				if err != nil {
+					return nil, err
+				}
+				city, err := db.City(ip)
+				return &Record{CountryShort: country, CountryLong: "Private", City: city}, err
+			},
+		}
+		for name, lookup := range lookups {
+			x, err := lookup()
+			if err != nil || *x != private {
+				t.Errorf("%s %s: got %+v, %v, want %+v", name, ip, x, err, private)
+			}
+		}
+
+		if ok, err := db.Contains(ip); !ok || err != nil {
+			t.Errorf("Contains %s: got %v, %v", ip, ok, err)
+		}
+		if _, _, err := db.RawRow(ip); err != ErrIPNotFound {
+			t.Errorf("RawRow %s: got %v, want ErrIPNotFound", ip, err)
+		}
+		if x, err := plain.GetAll(ip); err != nil || x.CountryShort != "-" {
+			t.Errorf("%s without the shortcut: got %+v, %v", ip, x, err)
+		}
+	}
+
+	r, err := db.GetAllRange("172.20.1.2")
+	if err != nil || r.IPFrom.String() != "172.16.0.0" || r.IPTo.String() != "172.31.255.255" {
+		t.Errorf("GetAllRange 172.20.1.2: got %+v, %v", r, err)
+	}
+	r, err = db.GetAllRange("ff02::1")
+	if err != nil || r.IPFrom.String() != "ff00::" || r.IPTo.String() != "ffff:ffff:ffff:ffff:ffff:ffff:ffff:ffff" {
+		t.Errorf("GetAllRange ff02::1: got %+v, %v", r, err)
+	}
+	if x, err := db.GetAllByUint32(0x0a000001); err != nil || *x != private {
+		t.Errorf("GetAllByUint32 10.0.0.1: got %+v, %v", x, err)
+	}
+	if x, err := db.GetAllByBigInt(big.NewInt(1)); err != nil || *x != private {
+		t.Errorf("GetAllByBigInt ::1: got %+v, %v", x, err)
+	}
+	if same, err := db.SameRange("10.0.0.1", "10.9.9.9"); !same || err != nil {
+		t.Errorf("SameRange: got %v, %v", same, err)
+	}
+
+	// public addresses and scans of the table are not affected
+	if x, err := db.GetAll("8.8.8.8"); err != nil || x.CountryShort != "US" {
+		t.Errorf("GetAll 8.8.8.8: got %+v, %v", x, err)
+	}
+	ranges, err := db.GetAllInRange("9.0.0.0", "11.0.0.0")
+	if err != nil || len(ranges) != 1 || ranges[0].Record.CountryShort != "-" {
+		t.Errorf("GetAllInRange: got %+v, %v", ranges, err)
+	}
+	db.Iterate(func(from, _ netip.Addr, x *Record) bool {
+		if x.CountryShort == "ZZ" {
+			t.Errorf("Iterate: range from %v has the shortcut record", from)
+		}
+		return true
+	})
+}
@@ -0,0 +1,48 @@
+package ip2location
+
+// product code in the header of IP2Proxy (PX) databases
+const productIP2Proxy = 2
+
+// Column positions of the IP2Proxy PX1-PX12 databases, indexed by database
+// type like the DB1-DB24 tables. PX types overlap DB types, so they are only
+// used when the header carries the IP2Proxy product code, which databases
+// built before 2021 lack.
+var (
+	proxyCountryPosition   = [13]uint8{0, 2, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3}
+	proxyRegionPosition    = [13]uint8{0, 0, 0, 4, 4, 4, 4, 4, 4, 4, 4, 4, 4}
+	proxyCityPosition      = [13]uint8{0, 0, 0, 5, 5, 5, 5, 5, 5, 5, 5, 5, 5}
+	proxyISPPosition       = [13]uint8{0, 0, 0, 0, 6, 6, 6, 6, 6, 6, 6, 6, 6}
+	proxyTypePosition      = [13]uint8{0, 0, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2}
+	proxyDomainPosition    = [13]uint8{0, 0, 0, 0, 0, 7, 7, 7, 7, 7, 7, 7, 7}
+	proxyUsageTypePosition = [13]uint8{0, 0, 0, 0, 0, 0, 8, 8, 8, 8, 8, 8, 8}
+	proxyThreatPosition    = [13]uint8{0, 0, 0, 0, 0, 0, 0, 0, 0, 12, 12, 12, 12}
+	proxyProviderPosition  = [13]uint8{0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 13, 13}
+
+	// highest column of each PX type, including columns this package
+	// does not decode (ASN, AS, last seen, fraud score)
+	proxyColumns = [13]uint8{0, 2, 3, 5, 6, 7, 8, 10, 11, 12, 12, 13, 14}
+)
+
+// set the column offsets and feature flags for IP2Proxy database type dbt
+func (db *DB) setProxyColumns(dbt uint8) {
+	for _, c := range []struct {
+		pos     [13]uint8
+		offset  *uint32
+		enabled *bool
+	}{
+		{proxyCountryPosition, &db.countryPositionOffset, &db.countryEnabled},
+		{proxyRegionPosition, &db.regionPositionOffset, &db.regionEnabled},
+		{proxyCityPosition, &db.cityPositionOffset, &db.cityEnabled},
+		{proxyISPPosition, &db.ispPositionOffset, &db.ispEnabled},
+		{proxyTypePosition, &db.proxyTypePositionOffset, &db.proxyTypeEnabled},
+		{proxyDomainPosition, &db.domainPositionOffset, &db.domainEnabled},
+		{proxyUsageTypePosition, &db.usageTypePositionOffset, &db.usageTypeEnabled},
+		{proxyThreatPosition, &db.threatPositionOffset, &db.threatEnabled},
+		{proxyProviderPosition, &db.providerPositionOffset, &db.providerEnabled},
+	} {
+		if c.pos[dbt] != 0 {
+			*c.offset = uint32(c.pos[dbt]-1) << 2
+			*c.enabled = true
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package ip2location
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestProxy(t *testing.T) {
+	notProxy := Record{CountryShort: "-", CountryLong: "-", Region: "-", City: "-", Isp: "-", Domain: "-", UsageType: "-", ProxyType: "-", Threat: "-", Provider: "-"}
+	vpn := Record{CountryShort: "AU", CountryLong: "Australia", Region: "Queensland", City: "Brisbane",
+		Isp: "Example ISP", Domain: "example.com", UsageType: "DCH", ProxyType: "VPN", Threat: "SPAM", Provider: "Example VPN"}
+	ranges := func(fields Field) []testRange {
+		return []testRange{
+			{From: "0.0.0.0", Rec: masked(notProxy, fields)},
+			{From: "1.0.0.0", Rec: masked(vpn, fields)},
+			{From: "1.0.1.0", Rec: masked(notProxy, fields)},
+		}
+	}
+
+	for _, tt := range []struct {
+		dbType uint8
+		fields Field
+	}{
+		{1, FieldCountryShort | FieldCountryLong},
+		{2, FieldCountryShort | FieldCountryLong | FieldProxyType},
+		{4, FieldCountryShort | FieldCountryLong | FieldRegion | FieldCity | FieldISP | FieldProxyType},
+		{8, FieldCountryShort | FieldCountryLong | FieldRegion | FieldCity | FieldISP | FieldDomain | FieldUsageType | FieldProxyType},
+		{9, FieldCountryShort | FieldCountryLong | FieldRegion | FieldCity | FieldISP | FieldDomain | FieldUsageType | FieldProxyType | FieldThreat},
+		{11, FieldCountryShort | FieldCountryLong | FieldRegion | FieldCity | FieldISP | FieldDomain | FieldUsageType | FieldProxyType | FieldThreat | FieldProvider},
+	} {
+		for _, index := range []bool{false, true} {
+			d := testDB{Type: tt.dbType, Product: productIP2Proxy, V4: ranges(tt.fields), V6: []testRange{{From: "::", Rec: masked(notProxy, tt.fields)}}, Index: index}
+			db := d.open(t, WithStrictValidation())
+			if name := db.DatabaseTypeName(); name != fmt.Sprintf("PX%d", tt.dbType) {
+				t.Errorf("PX%d: DatabaseTypeName = %q", tt.dbType, name)
+			}
+			if got := db.Fields(); got != tt.fields {
+				t.Errorf("PX%d: Fields = %v, want %v", tt.dbType, got, tt.fields)
+			}
+			for ip, want := range map[string]Record{
+				"1.0.0.5":        masked(vpn, tt.fields),
+				"1.0.1.5":        masked(notProxy, tt.fields),
+				"2001:db8::1":    masked(notProxy, tt.fields),
+				"::ffff:1.0.0.1": masked(vpn, tt.fields),
+			} {
+				x, err := db.GetAll(ip)
+				if err != nil {
+					t.Fatalf("PX%d, %s: %v", tt.dbType, ip, err)
+				}
+				if *x != want {
+					t.Errorf("PX%d index %v, %s: got %+v, want %+v", tt.dbType, index, ip, *x, want)
+				}
+			}
+		}
+	}
+
+	// the same type without the product code is read as DB2, whose second
+	// column is the ISP
+	d := testDB{Type: 2, V4: []testRange{{From: "0.0.0.0", Rec: Record{CountryShort: "AU", CountryLong: "Australia", Isp: "VPN"}}}}
+	x, err := d.open(t).GetAll("1.0.0.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if x.Isp != "VPN" || x.ProxyType != "" {
+		t.Errorf("DB2: got %+v", *x)
+	}
+}
+
+// x with only the given fields
+func masked(x Record, fields Field) Record {
+	var y Record
+	for _, e := range fieldNames {
+		if fields&e.field == 0 {
+			continue
+		}
+		if str, f := x.fieldPtr(e.field); str != nil {
+			s, _ := y.fieldPtr(e.field)
+			*s = *str
+		} else {
+			_, g := y.fieldPtr(e.field)
+			*g = *f
+		}
+	}
+	return y
+}
@@ -0,0 +1,306 @@
+package ip2location
+
+import (
+	"fmt"
+	"math/big"
+	"net/netip"
+	"strings"
+)
+
+// RangeRecord is a Record together with the database range it belongs to.
+// Every address in [IPFrom, IPTo] resolves to the same Record.
+type RangeRecord struct {
+	IPFrom netip.Addr // first address of the range
+	IPTo   netip.Addr // last address of the range, inclusive
+	Record *Record
+}
+
+// GetAllRange gets all fields for the IP address along with the bounds of the
+// range it was found in. On a miss, Record is empty and the bounds are invalid.
+func (db *DB) GetAllRange(ipaddress string) (*RangeRecord, error) {
+	iptype, ipno, ipindex := db.checkIP(ipaddress)
+	if iptype == 0 {
+		return nil, ErrInvalidAddress
+	}
+
+	rowoffset, ipfrom, ipto, found, err := db.search(iptype, ipno, ipindex)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return &RangeRecord{Record: &Record{}}, nil
+	}
+	x, err := db.readRecord(iptype, rowoffset, FieldAll)
+	if err != nil {
+		return nil, err
+	}
+	from, to := rangeAddrs(iptype, ipfrom, ipto)
+	return &RangeRecord{IPFrom: from, IPTo: to, Record: x}, nil
+}
+
+// SameRange reports whether both IP addresses fall in the same database
+// range, and so resolve to the same Record. It only searches, without
+// decoding any field. An address that is not found gives an error wrapping
+// ErrIPNotFound.
+func (db *DB) SameRange(ipA, ipB string) (bool, error) {
+	typeA, offA, err := db.locate(ipA)
+	if err != nil {
+		return false, err
+	}
+	typeB, offB, err := db.locate(ipB)
+	if err != nil {
+		return false, err
+	}
+	return typeA == typeB && offA == offB, nil
+}
+
+// the table and row offset of the range containing ipaddress
+func (db *DB) locate(ipaddress string) (uint32, uint32, error) {
+	iptype, ipno, ipindex := db.checkIP(ipaddress)
+	if iptype == 0 {
+		return 0, 0, ErrInvalidAddress
+	}
+	rowoffset, found, err := db.find(iptype, ipno, ipindex)
+	if err != nil {
+		return 0, 0, err
+	}
+	if !found {
+		return 0, 0, fmt.Errorf("%s: %w", ipaddress, ErrIPNotFound)
+	}
+	return iptype, rowoffset, nil
+}
+
+// convert the stored [ipfrom, ipto) bounds to inclusive addresses
+func rangeAddrs(iptype uint32, ipfrom, ipto *big.Int) (from, to netip.Addr) {
+	maxip := maxIpv4Range
+	if iptype == 6 {
+		maxip = maxIpv6Range
+	}
+	last := new(big.Int).Set(ipto)
+	// the top address is matched by the last range, see search
+	if last.Cmp(maxip) < 0 {
+		last.Sub(last, big.NewInt(1))
+	}
+	return bigToAddr(iptype, ipfrom), bigToAddr(iptype, last)
+}
+
+// convert an IP number to an address of the given family
+func bigToAddr(iptype uint32, n *big.Int) netip.Addr {
+	if iptype == 4 {
+		var b [4]byte
+		n.FillBytes(b[:])
+		return netip.AddrFrom4(b)
+	}
+	var b [16]byte
+	n.FillBytes(b[:])
+	return netip.AddrFrom16(b)
+}
+
+// Prefixes returns the minimal list of CIDR blocks covering the range.
+func (r *RangeRecord) Prefixes() []netip.Prefix {
+	return RangeToPrefixes(r.IPFrom, r.IPTo)
+}
+
+// RangeToPrefixes returns the minimal list of CIDR blocks exactly covering the
+// inclusive range [from, to]. It returns nil if the addresses are invalid, of
+// different families, or from > to.
+func RangeToPrefixes(from, to netip.Addr) []netip.Prefix {
+	if !from.IsValid() || !to.IsValid() || from.Is4() != to.Is4() || to.Less(from) {
+		return nil
+	}
+
+	iptype, bits := uint32(6), 128
+	if from.Is4() {
+		iptype, bits = 4, 32
+	}
+	start := new(big.Int).SetBytes(from.AsSlice())
+	end := new(big.Int).SetBytes(to.AsSlice())
+	one := big.NewInt(1)
+	size := new(big.Int)
+
+	var prefixes []netip.Prefix
+	for start.Cmp(end) <= 0 {
+		// largest block aligned at start...
+		host := int(start.TrailingZeroBits())
+		if start.Sign() == 0 {
+			host = bits
+		}
+		// ...that does not run past end
+		for ; host > 0; host-- {
+			size.Lsh(one, uint(host))
+			size.Add(size, start)
+			size.Sub(size, one)
+			if size.Cmp(end) <= 0 {
+				break
+			}
+		}
+		prefixes = append(prefixes, netip.PrefixFrom(bigToAddr(iptype, start), bits-host))
+		size.Lsh(one, uint(host))
+		start.Add(start, size)
+	}
+	return prefixes
+}
+
+// RangesForCountry returns the minimal list of CIDR blocks covering every
+// range whose country code matches code, ignoring case. Adjacent ranges are
+// coalesced before conversion. It scans the whole database, so it is O(n) in
+// the number of rows and intended for offline use such as building allow or
+// deny lists.
+func (db *DB) RangesForCountry(code string) ([]netip.Prefix, error) {
+	var prefixes []netip.Prefix
+	var start, end netip.Addr // current run of adjacent matching ranges
+
+	err := db.Iterate(func(from, to netip.Addr, rec *Record) bool {
+		if !strings.EqualFold(rec.CountryShort, code) {
+			return true
+		}
+		if end.IsValid() && end.Next() == from {
+			end = to
+			return true
+		}
+		if start.IsValid() {
+			prefixes = append(prefixes, RangeToPrefixes(start, end)...)
+		}
+		start, end = from, to
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	if start.IsValid() {
+		prefixes = append(prefixes, RangeToPrefixes(start, end)...)
+	}
+	return prefixes, nil
+}
+
+// LookupPrefix returns every range overlapping the prefix, each exactly once
+// and in ascending order, with its Record. The first and last ranges may
+// extend beyond the prefix. It costs one search plus one row read per range,
+// far less than looking up each address of the prefix. IPv4-mapped prefixes
+// of at least 96 bits, such as ::ffff:1.2.3.0/120, are looked up as IPv4, as
+// GetAll does for their addresses.
+func (db *DB) LookupPrefix(p netip.Prefix) ([]RangeRecord, error) {
+	if !p.IsValid() {
+		return nil, ErrInvalidAddress
+	}
+	p = p.Masked()
+	if p.Addr().Is4In6() && p.Bits() >= 96 {
+		p = netip.PrefixFrom(p.Addr().Unmap(), p.Bits()-96)
+	}
+	first := p.Addr()
+	iptype, bits := uint32(6), 128
+	if first.Is4() {
+		iptype, bits = 4, 32
+	}
+
+	// last address of the prefix: the first with all host bits set
+	ipno := new(big.Int).SetBytes(first.AsSlice())
+	host := new(big.Int).Lsh(big.NewInt(1), uint(bits-p.Bits()))
+	last := bigToAddr(iptype, host.Sub(host.Add(host, ipno), big.NewInt(1)))
+	return db.lookupSpan(iptype, first, last)
+}
+
+// GetAllInRange is like LookupPrefix for the inclusive range [start, end]
+// of addresses of the same family; IPv4-mapped IPv6 addresses count as IPv4.
+func (db *DB) GetAllInRange(start, end string) ([]RangeRecord, error) {
+	first, err := netip.ParseAddr(start)
+	if err != nil {
+		return nil, ErrInvalidAddress
+	}
+	last, err := netip.ParseAddr(end)
+	if err != nil {
+		return nil, ErrInvalidAddress
+	}
+	first, last = first.Unmap(), last.Unmap()
+	if first.Is4() != last.Is4() {
+		return nil, ErrWrongIPFamily
+	}
+	if last.Less(first) {
+		return nil, fmt.Errorf("ip2location: range start %s after end %s", first, last)
+	}
+	iptype := uint32(6)
+	if first.Is4() {
+		iptype = 4
+	}
+	return db.lookupSpan(iptype, first.WithZone(""), last.WithZone(""))
+}
+
+// the ranges overlapping [first, last], in ascending order
+func (db *DB) lookupSpan(iptype uint32, first, last netip.Addr) ([]RangeRecord, error) {
+	ipno := new(big.Int).SetBytes(first.AsSlice())
+	rowoffset, found, err := db.findRow(iptype, ipno, db.indexAddr(iptype, ipno))
+	if err != nil {
+		return nil, err
+	}
+	baseaddr, colsize := db.meta.ipv4DatabaseAddr, db.meta.ipv4ColumnsSize
+	if iptype == 6 {
+		baseaddr, colsize = db.meta.ipv6DatabaseAddr, db.meta.ipv6ColumnSize
+	}
+	// when first falls in a gap, start with the range after it
+	var start uint32
+	if found {
+		start = (rowoffset - baseaddr) / colsize
+	} else if rowoffset != 0 {
+		start = (rowoffset-baseaddr)/colsize + 1
+	}
+
+	var ranges []RangeRecord
+	_, err = db.iterateRows(iptype, start, func(from, to netip.Addr, rec *Record) bool {
+		if last.Less(from) {
+			return false
+		}
+		ranges = append(ranges, RangeRecord{IPFrom: from, IPTo: to, Record: rec})
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ranges, nil
+}
+
+// DominantCountry returns the country code covering the largest part of the
+// prefix and the fraction of the prefix it covers. Ties go to the code that
+// sorts first. It returns "" and 0 if no range overlaps the prefix.
+func (db *DB) DominantCountry(p netip.Prefix) (code string, coverage float64, err error) {
+	ranges, err := db.LookupPrefix(p)
+	if err != nil {
+		return "", 0, err
+	}
+	p = p.Masked()
+	first := new(big.Int).SetBytes(p.Addr().AsSlice())
+	size := new(big.Int).Lsh(big.NewInt(1), uint(p.Addr().BitLen()-p.Bits()))
+	last := new(big.Int).Add(first, size)
+	last.Sub(last, big.NewInt(1))
+
+	// addresses of the prefix covered by each country
+	counts := make(map[string]*big.Int)
+	for _, r := range ranges {
+		from := new(big.Int).SetBytes(r.IPFrom.AsSlice())
+		to := new(big.Int).SetBytes(r.IPTo.AsSlice())
+		if from.Cmp(first) < 0 {
+			from = first
+		}
+		if to.Cmp(last) > 0 {
+			to = last
+		}
+		n := new(big.Int).Sub(to, from)
+		n.Add(n, big.NewInt(1))
+		c := r.Record.CountryShort
+		if counts[c] == nil {
+			counts[c] = new(big.Int)
+		}
+		counts[c].Add(counts[c], n)
+	}
+
+	var best *big.Int
+	for c, n := range counts {
+		if best == nil || n.Cmp(best) > 0 || (n.Cmp(best) == 0 && c < code) {
+			code, best = c, n
+		}
+	}
+	if best == nil {
+		return "", 0, nil
+	}
+	coverage, _ = new(big.Rat).SetFrac(best, size).Float64()
+	return code, coverage, nil
+}
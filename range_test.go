@@ -0,0 +1,109 @@
+package ip2location
+
+import (
+	"net/netip"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestRangeToPrefixes(t *testing.T) {
+	for _, c := range []struct {
+		from, to string
+		want     string // space-separated prefixes
+	}{
+		{"10.0.0.1", "10.0.0.1", "10.0.0.1/32"},
+		{"::1", "::1", "::1/128"},
+		{"0.0.0.0", "255.255.255.255", "0.0.0.0/0"},
+		{"::", "ffff:ffff:ffff:ffff:ffff:ffff:ffff:ffff", "::/0"},
+		{"1.0.0.0", "1.0.0.255", "1.0.0.0/24"},
+		{"10.0.0.1", "10.0.0.6", "10.0.0.1/32 10.0.0.2/31 10.0.0.4/31 10.0.0.6/32"},
+		{"10.0.0.3", "10.0.1.0", "10.0.0.3/32 10.0.0.4/30 10.0.0.8/29 10.0.0.16/28 10.0.0.32/27 10.0.0.64/26 10.0.0.128/25 10.0.1.0/32"},
+		{"0.0.0.1", "255.255.255.255", "0.0.0.1/32 0.0.0.2/31 0.0.0.4/30 0.0.0.8/29 0.0.0.16/28 0.0.0.32/27 0.0.0.64/26 0.0.0.128/25 " +
+			"0.0.1.0/24 0.0.2.0/23 0.0.4.0/22 0.0.8.0/21 0.0.16.0/20 0.0.32.0/19 0.0.64.0/18 0.0.128.0/17 " +
+			"0.1.0.0/16 0.2.0.0/15 0.4.0.0/14 0.8.0.0/13 0.16.0.0/12 0.32.0.0/11 0.64.0.0/10 0.128.0.0/9 " +
+			"1.0.0.0/8 2.0.0.0/7 4.0.0.0/6 8.0.0.0/5 16.0.0.0/4 32.0.0.0/3 64.0.0.0/2 128.0.0.0/1"},
+		{"2001:db8::", "2001:db8::1:2", "2001:db8::/112 2001:db8::1:0/127 2001:db8::1:2/128"},
+		{"10.0.0.2", "10.0.0.1", ""},
+		{"10.0.0.1", "::1", ""},
+	} {
+		got := RangeToPrefixes(netip.MustParseAddr(c.from), netip.MustParseAddr(c.to))
+		var want []netip.Prefix
+		for _, s := range strings.Fields(c.want) {
+			want = append(want, netip.MustParsePrefix(s))
+		}
+		if !slices.Equal(got, want) {
+			t.Errorf("%s-%s: got %v, want %v", c.from, c.to, got, want)
+		}
+	}
+}
+
+func TestGetAllRange(t *testing.T) {
+	db := testDB5.open(t)
+	for _, c := range []struct {
+		ip, from, to string
+		prefixes     string
+	}{
+		{"1.0.0.5", "1.0.0.0", "1.0.0.255", "1.0.0.0/24"},
+		{"5.6.7.8", "1.0.1.0", "8.8.7.255", "1.0.1.0/24 1.0.2.0/23 1.0.4.0/22 1.0.8.0/21 1.0.16.0/20 1.0.32.0/19 1.0.64.0/18 1.0.128.0/17 " +
+			"1.1.0.0/16 1.2.0.0/15 1.4.0.0/14 1.8.0.0/13 1.16.0.0/12 1.32.0.0/11 1.64.0.0/10 1.128.0.0/9 " +
+			"2.0.0.0/7 4.0.0.0/6 8.0.0.0/13 8.8.0.0/21"},
+		{"2001:4860:4860::8888", "2001:4860::", "2001:4860:ffff:ffff:ffff:ffff:ffff:ffff", "2001:4860::/32"},
+	} {
+		r, err := db.GetAllRange(c.ip)
+		if err != nil {
+			t.Fatal(c.ip, err)
+		}
+		if r.IPFrom.String() != c.from || r.IPTo.String() != c.to {
+			t.Errorf("%s: got %v-%v, want %s-%s", c.ip, r.IPFrom, r.IPTo, c.from, c.to)
+		}
+		got := r.Prefixes()
+		var want []netip.Prefix
+		for _, s := range strings.Fields(c.prefixes) {
+			want = append(want, netip.MustParsePrefix(s))
+		}
+		if !slices.Equal(got, want) {
+			t.Errorf("%s: got %v, want %v", c.ip, got, want)
+		}
+	}
+}
+
+func TestLookupPrefix(t *testing.T) {
+	db := testDB5.open(t)
+	for _, tt := range []struct {
+		prefix string
+		want   []string // first address and country of each range
+	}{
+		{"1.0.0.0/23", []string{"1.0.0.0 AU", "1.0.1.0 CN"}},
+		{"1.0.1.128/25", []string{"1.0.1.0 CN"}},
+		{"8.8.8.8/32", []string{"8.8.8.0 US"}},
+		{"2001:4860::/31", []string{"2001:4860:: US", "2001:4861:: -"}},
+
+		// IPv4-mapped prefixes are looked up as IPv4, like GetAll does
+		{"::ffff:1.0.0.0/119", []string{"1.0.0.0 AU", "1.0.1.0 CN"}},
+		{"::ffff:8.8.8.0/120", []string{"8.8.8.0 US"}},
+		{"::ffff:8.8.8.8/128", []string{"8.8.8.0 US"}},
+		{"::/64", []string{":: -"}},
+	} {
+		rs, err := db.LookupPrefix(netip.MustParsePrefix(tt.prefix))
+		if err != nil {
+			t.Errorf("%s: %v", tt.prefix, err)
+			continue
+		}
+		var got []string
+		for _, r := range rs {
+			got = append(got, r.IPFrom.String()+" "+r.Record.CountryShort)
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("%s: got %q, want %q", tt.prefix, got, tt.want)
+		}
+	}
+
+	x, err := db.GetAll("::ffff:8.8.8.8")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if x.CountryShort != "US" {
+		t.Errorf("GetAll(::ffff:8.8.8.8) = %q, want the LookupPrefix answer US", x.CountryShort)
+	}
+}
@@ -0,0 +1,155 @@
+package ip2location
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// RawRow returns the bytes of the row matching the IP address, exactly as
+// stored, together with its 1-based offset in the file. IPv4 rows are
+// 4*columns bytes long; IPv6 rows are 12 bytes longer since their first column
+// is 16 bytes wide. It returns ErrIPNotFound when the search misses, and for
+// addresses answered by WithPrivateIPShortcut, which have no row.
+func (db *DB) RawRow(ipaddress string) ([]byte, uint32, error) {
+	iptype, ipno, ipindex := db.checkIP(ipaddress)
+	if iptype == 0 {
+		return nil, 0, ErrInvalidAddress
+	}
+
+	rowoffset, found, err := db.find(iptype, ipno, ipindex)
+	if err != nil {
+		return nil, 0, err
+	}
+	if !found || rowoffset == privateRow {
+		return nil, 0, ErrIPNotFound
+	}
+
+	colsize := db.meta.ipv4ColumnsSize
+	if iptype == 6 {
+		colsize = db.meta.ipv6ColumnSize
+	}
+	row := make([]byte, colsize)
+	if _, err := db.readAt(row, int64(rowoffset)-1); err != nil {
+		return nil, 0, readErr(int64(rowoffset)-1, err)
+	}
+	return row, rowoffset, nil
+}
+
+// ParseRow decodes the requested fields of a single row, as returned by
+// RawRow, of a database of type dbType. The row may be an IPv4 or an IPv6 row;
+// the two are told apart by length. Rows are little-endian.
+//
+// String columns only hold pointers into the rest of the database, so the
+// row alone is not enough to decode them: strs must serve the same offsets as
+// the database file, such as the file itself or a bytes.Reader over its
+// contents. If strs is nil only the inline latitude and longitude columns are
+// decoded, and the other requested fields are left empty.
+func ParseRow(data []byte, dbType uint8, fields Field, strs io.ReaderAt) (*Record, error) {
+	if dbType < 1 || int(dbType) >= len(countryPosition) {
+		return nil, fmt.Errorf("%w: unsupported database type %d", ErrInvalidDatabase, dbType)
+	}
+	cols := int(typeColumns(dbType))
+	switch len(data) {
+	case cols * 4:
+	case 16 + (cols-1)*4:
+		data = data[12:] // skip the wider IPv6 first column, see readRecordInto
+	default:
+		return nil, fmt.Errorf("%w: row of %d bytes for database type %d", ErrInvalidDatabase, len(data), dbType)
+	}
+
+	db := &DB{file: strs, order: binary.LittleEndian, size: -1, meta: &dbMeta{databaseType: dbType}}
+	if size, ok := readerSize(strs); ok {
+		db.size = size
+	}
+	db.setColumns(dbType)
+	if strs == nil {
+		fields &= FieldLatitude | FieldLongitude
+	}
+	x := &Record{}
+	if err := db.decodeRow(x, data, fields); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// ColumnPointers returns, for the row matching the IP address, the string
+// pointer stored in each column the database carries, keyed by canonical
+// field name as in FieldMap. The pointers are the 0-based file offsets of
+// the length-prefixed strings; CountryLong shares the CountryShort column and
+// is reported at the offset it is read from, 3 bytes further. Latitude and
+// longitude are stored inline and are not included. It returns
+// ErrIPNotFound when the search misses.
+func (db *DB) ColumnPointers(ipaddress string) (map[string]uint32, error) {
+	row, _, err := db.RawRow(ipaddress)
+	if err != nil {
+		return nil, err
+	}
+	if len(row) == int(db.meta.ipv6ColumnSize) {
+		row = row[12:] // skip the wider IPv6 first column, see readRecordInto
+	}
+
+	m := make(map[string]uint32)
+	for _, e := range fieldNames {
+		off, ok := db.pointerColumn(e.field)
+		if !ok {
+			continue
+		}
+		u32, err := db.column(row, off)
+		if err != nil {
+			return nil, err
+		}
+		if e.field == FieldCountryLong {
+			u32 += 3
+		}
+		m[e.name] = u32
+	}
+	return m, nil
+}
+
+// offset in a row of the string pointer column for f, if the database has one
+func (db *DB) pointerColumn(f Field) (uint32, bool) {
+	switch f {
+	case FieldCountryShort, FieldCountryLong:
+		return db.countryPositionOffset, db.countryEnabled
+	case FieldRegion:
+		return db.regionPositionOffset, db.regionEnabled
+	case FieldCity:
+		return db.cityPositionOffset, db.cityEnabled
+	case FieldISP:
+		return db.ispPositionOffset, db.ispEnabled
+	case FieldDomain:
+		return db.domainPositionOffset, db.domainEnabled
+	case FieldZipCode:
+		return db.zipcodePositionOffset, db.zipCodeEnabled
+	case FieldTimeZone:
+		return db.timeZonePositionOffset, db.timeZoneEnabled
+	case FieldNetSpeed:
+		return db.netSpeedPositionOffset, db.netSpeedEnabled
+	case FieldIDDCode:
+		return db.iddCodePositionOffset, db.iddCodeEnabled
+	case FieldAreaCode:
+		return db.areaCodePositionOffset, db.areaCodeEnabled
+	case FieldWeatherStationCode:
+		return db.weatherStationCodePositionOffset, db.weatherStationCodeEnabled
+	case FieldWeatherStationName:
+		return db.weatherStationNamePositionOffset, db.weatherStationNameEnabled
+	case FieldMCC:
+		return db.mccPositionOffset, db.mccEnabled
+	case FieldMNC:
+		return db.mncPositionOffset, db.mncEnabled
+	case FieldMobileBrand:
+		return db.mobileBrandPositionOffset, db.mobileBrandEnabled
+	case FieldElevation:
+		return db.elevationPositionOffset, db.elevationEnabled
+	case FieldUsageType:
+		return db.usageTypePositionOffset, db.usageTypeEnabled
+	case FieldProxyType:
+		return db.proxyTypePositionOffset, db.proxyTypeEnabled
+	case FieldThreat:
+		return db.threatPositionOffset, db.threatEnabled
+	case FieldProvider:
+		return db.providerPositionOffset, db.providerEnabled
+	}
+	return 0, false
+}
@@ -0,0 +1,64 @@
+package ip2location
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestParseRow(t *testing.T) {
+	data := testDB5.build()
+	db, err := OpenBytes(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	for _, ip := range []string{"8.8.8.8", "2001:4860::1"} {
+		row, _, err := db.RawRow(ip)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want, err := db.GetAll(ip)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		x, err := ParseRow(row, 5, FieldAll, bytes.NewReader(data))
+		if err != nil {
+			t.Fatalf("%s: %v", ip, err)
+		}
+		if *x != *want {
+			t.Errorf("%s: ParseRow = %+v, want %+v", ip, *x, *want)
+		}
+
+		// without the strings only the coordinates can be decoded
+		x, err = ParseRow(row, 5, FieldAll, nil)
+		if err != nil {
+			t.Fatalf("%s: %v", ip, err)
+		}
+		if *x != (Record{Latitude: want.Latitude, Longitude: want.Longitude}) {
+			t.Errorf("%s: ParseRow without strings = %+v", ip, *x)
+		}
+	}
+
+	row, _, err := db.RawRow("8.8.8.8")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range []struct {
+		name   string
+		row    []byte
+		dbType uint8
+	}{
+		{"short row", row[:len(row)-1], 5},
+		{"long row", append(row, 0), 5},
+		{"wrong type", row, 1},
+		{"type 0", row, 0},
+		{"type past the last", row, uint8(len(countryPosition))},
+	} {
+		if _, err := ParseRow(tt.row, tt.dbType, FieldAll, nil); !errors.Is(err, ErrInvalidDatabase) {
+			t.Errorf("%s: err = %v, want ErrInvalidDatabase", tt.name, err)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package ip2location
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// implements io.ReaderAt semantics on an in-memory slice
+func readAtBytes(data, p []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, errors.New("ip2location: negative offset")
+	}
+	if off >= int64(len(data)) {
+		return 0, io.EOF
+	}
+	n := copy(p, data[off:])
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+// bytesReader serves reads from a database held entirely in memory
+type bytesReader struct {
+	data []byte
+}
+
+func (b *bytesReader) ReadAt(p []byte, off int64) (int, error) {
+	return readAtBytes(b.data, p, off)
+}
+
+func (b *bytesReader) Size() int64 {
+	return int64(len(b.data))
+}
+
+// OpenBytes initializes the database from its contents in memory. All reads
+// are then served from data without any syscalls; the memory cost is the size
+// of the database. data must not be modified while the database is in use.
+// WithMmap has no effect here.
+func OpenBytes(data []byte, opts ...Option) (*DB, error) {
+	return newDB(&bytesReader{data: data}, nil, newOptions(opts))
+}
+
+// OpenGzip opens a gzip-compressed database file, decompressing it into
+// memory once and serving reads from there as OpenBytes does. The memory cost
+// is the uncompressed size of the database. WithMmap has no effect here.
+func OpenGzip(path string, opts ...Option) (*DB, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, fmt.Errorf("ip2location: %s: %w", path, err)
+	}
+	data, err := io.ReadAll(zr)
+	if err != nil {
+		return nil, fmt.Errorf("ip2location: %s: %w", path, err)
+	}
+	return OpenBytes(data, opts...)
+}
+
+// OpenReader initializes the database from any io.ReaderAt, such as a reader
+// backed by HTTP range requests or object storage. r must be safe for
+// concurrent use if the database is. If r has a Size() int64 or
+// Stat() (fs.FileInfo, error) method, the header is checked against the size.
+// If r is also an io.Closer, Close closes it.
+func OpenReader(r io.ReaderAt, opts ...Option) (*DB, error) {
+	c, _ := r.(io.Closer)
+	return newDB(r, c, newOptions(opts))
+}
+
+// return the n bytes at off of an in-memory database
+func (db *DB) slice(off int64, n int) ([]byte, error) {
+	if off < 0 {
+		return nil, readErr(off, errors.New("ip2location: negative offset"))
+	}
+	if off+int64(n) > int64(len(db.data)) {
+		return nil, readErr(off, io.ErrUnexpectedEOF)
+	}
+	return db.data[off : off+int64(n)], nil
+}
+
+// timeoutReader fails reads from r that take longer than timeout
+type timeoutReader struct {
+	r       io.ReaderAt
+	timeout time.Duration
+}
+
+type readResult struct {
+	n   int
+	err error
+}
+
+func (t *timeoutReader) ReadAt(p []byte, off int64) (int, error) {
+	// read into a private buffer: p may be reused by the caller once we give
+	// up on a stalled read
+	buf := make([]byte, len(p))
+	done := make(chan readResult, 1)
+	go func() {
+		n, err := t.r.ReadAt(buf, off)
+		done <- readResult{n, err}
+	}()
+
+	timer := time.NewTimer(t.timeout)
+	defer timer.Stop()
+	select {
+	case res := <-done:
+		copy(p, buf[:res.n])
+		return res.n, res.err
+	case <-timer.C:
+		return 0, fmt.Errorf("ip2location: read timed out after %s: %w", t.timeout, os.ErrDeadlineExceeded)
+	}
+}
@@ -0,0 +1,129 @@
+package ip2location
+
+import (
+	"strings"
+	"sync"
+)
+
+type regionKey struct {
+	country string // ISO 3166-1 alpha-2 code
+	region  string // region name as stored in the database
+}
+
+// the key of a country and region pair in regionCodes, ignoring case
+func newRegionKey(country, region string) regionKey {
+	return regionKey{strings.ToUpper(country), strings.ToLower(region)}
+}
+
+// ISO 3166-2 codes by country code and region name, as registered with
+// RegisterRegionCode and keyed by newRegionKey
+var regionCodes = struct {
+	mu sync.RWMutex
+	m  map[regionKey]string
+}{m: builtinRegionCodes()}
+
+// the built-in entries, keyed by newRegionKey
+func builtinRegionCodes() map[regionKey]string {
+	m := make(map[regionKey]string, len(defaultRegionCodes))
+	for k, code := range defaultRegionCodes {
+		m[newRegionKey(k.country, k.region)] = code
+	}
+	return m
+}
+
+// the built-in ISO 3166-2 codes, covering the United States, Canada and
+// Australia
+var defaultRegionCodes = map[regionKey]string{
+	{"US", "Alabama"}:                      "US-AL",
+	{"US", "Alaska"}:                       "US-AK",
+	{"US", "Arizona"}:                      "US-AZ",
+	{"US", "Arkansas"}:                     "US-AR",
+	{"US", "California"}:                   "US-CA",
+	{"US", "Colorado"}:                     "US-CO",
+	{"US", "Connecticut"}:                  "US-CT",
+	{"US", "Delaware"}:                     "US-DE",
+	{"US", "District of Columbia"}:         "US-DC",
+	{"US", "Florida"}:                      "US-FL",
+	{"US", "Georgia"}:                      "US-GA",
+	{"US", "Hawaii"}:                       "US-HI",
+	{"US", "Idaho"}:                        "US-ID",
+	{"US", "Illinois"}:                     "US-IL",
+	{"US", "Indiana"}:                      "US-IN",
+	{"US", "Iowa"}:                         "US-IA",
+	{"US", "Kansas"}:                       "US-KS",
+	{"US", "Kentucky"}:                     "US-KY",
+	{"US", "Louisiana"}:                    "US-LA",
+	{"US", "Maine"}:                        "US-ME",
+	{"US", "Maryland"}:                     "US-MD",
+	{"US", "Massachusetts"}:                "US-MA",
+	{"US", "Michigan"}:                     "US-MI",
+	{"US", "Minnesota"}:                    "US-MN",
+	{"US", "Mississippi"}:                  "US-MS",
+	{"US", "Missouri"}:                     "US-MO",
+	{"US", "Montana"}:                      "US-MT",
+	{"US", "Nebraska"}:                     "US-NE",
+	{"US", "Nevada"}:                       "US-NV",
+	{"US", "New Hampshire"}:                "US-NH",
+	{"US", "New Jersey"}:                   "US-NJ",
+	{"US", "New Mexico"}:                   "US-NM",
+	{"US", "New York"}:                     "US-NY",
+	{"US", "North Carolina"}:               "US-NC",
+	{"US", "North Dakota"}:                 "US-ND",
+	{"US", "Ohio"}:                         "US-OH",
+	{"US", "Oklahoma"}:                     "US-OK",
+	{"US", "Oregon"}:                       "US-OR",
+	{"US", "Pennsylvania"}:                 "US-PA",
+	{"US", "Rhode Island"}:                 "US-RI",
+	{"US", "South Carolina"}:               "US-SC",
+	{"US", "South Dakota"}:                 "US-SD",
+	{"US", "Tennessee"}:                    "US-TN",
+	{"US", "Texas"}:                        "US-TX",
+	{"US", "Utah"}:                         "US-UT",
+	{"US", "Vermont"}:                      "US-VT",
+	{"US", "Virginia"}:                     "US-VA",
+	{"US", "Washington"}:                   "US-WA",
+	{"US", "West Virginia"}:                "US-WV",
+	{"US", "Wisconsin"}:                    "US-WI",
+	{"US", "Wyoming"}:                      "US-WY",
+	{"CA", "Alberta"}:                      "CA-AB",
+	{"CA", "British Columbia"}:             "CA-BC",
+	{"CA", "Manitoba"}:                     "CA-MB",
+	{"CA", "New Brunswick"}:                "CA-NB",
+	{"CA", "Newfoundland and Labrador"}:    "CA-NL",
+	{"CA", "Northwest Territories"}:        "CA-NT",
+	{"CA", "Nova Scotia"}:                  "CA-NS",
+	{"CA", "Nunavut"}:                      "CA-NU",
+	{"CA", "Ontario"}:                      "CA-ON",
+	{"CA", "Prince Edward Island"}:         "CA-PE",
+	{"CA", "Quebec"}:                       "CA-QC",
+	{"CA", "Saskatchewan"}:                 "CA-SK",
+	{"CA", "Yukon"}:                        "CA-YT",
+	{"AU", "Australian Capital Territory"}: "AU-ACT",
+	{"AU", "New South Wales"}:              "AU-NSW",
+	{"AU", "Northern Territory"}:           "AU-NT",
+	{"AU", "Queensland"}:                   "AU-QLD",
+	{"AU", "South Australia"}:              "AU-SA",
+	{"AU", "Tasmania"}:                     "AU-TAS",
+	{"AU", "Victoria"}:                     "AU-VIC",
+	{"AU", "Western Australia"}:            "AU-WA",
+}
+
+// RegisterRegionCode maps the region name of a country, as found in
+// Record.Region, to its ISO 3166-2 subdivision code, e.g. ("FR",
+// "Ile-de-France", "FR-IDF"). Both names are matched ignoring case, and
+// existing entries are replaced. It is safe to call concurrently with
+// RegionCode.
+func RegisterRegionCode(countryShort, region, code string) {
+	regionCodes.mu.Lock()
+	defer regionCodes.mu.Unlock()
+	regionCodes.m[newRegionKey(countryShort, region)] = code
+}
+
+// RegionCode returns the ISO 3166-2 subdivision code of the region, such as
+// "US-CA" for California, or "" if the country and region pair is unknown.
+// The country code and region name are matched ignoring case.
+func (x *Record) RegionCode() string {
+	regionCodes.mu.RLock()
+	defer regionCodes.mu.RUnlock()
+	return regionCodes.m[newRegionKey(x.CountryShort, x.Region)]
+}
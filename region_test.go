@@ -0,0 +1,48 @@
+package ip2location
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestRegionCode(t *testing.T) {
+	RegisterRegionCode("fr", "Ile-de-France", "FR-IDF")
+	for _, c := range []struct {
+		country, region, want string
+	}{
+		{"US", "California", "US-CA"},
+		{"us", "CALIFORNIA", "US-CA"},
+		{"AU", "new south wales", "AU-NSW"},
+		{"FR", "ile-de-france", "FR-IDF"},
+		{"Fr", "ILE-DE-FRANCE", "FR-IDF"},
+		{"CA", "California", ""},
+		{"-", "-", ""},
+	} {
+		x := &Record{CountryShort: c.country, Region: c.region}
+		if got := x.RegionCode(); got != c.want {
+			t.Errorf("%s %s: got %q, want %q", c.country, c.region, got, c.want)
+		}
+	}
+}
+
+// run with -race
+func TestRegisterRegionCodeConcurrently(t *testing.T) {
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for range 100 {
+			RegisterRegionCode("NZ", "Auckland", "NZ-AUK")
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		x := &Record{CountryShort: "NZ", Region: "auckland"}
+		for range 100 {
+			if got := x.RegionCode(); got != "" && got != "NZ-AUK" {
+				t.Errorf("got %q", got)
+			}
+		}
+	}()
+	wg.Wait()
+}
@@ -0,0 +1,55 @@
+package ip2location
+
+import "time"
+
+// Resolved is a Record along with the typed forms of its fields. Typed fields
+// the database does not carry, or whose raw value is missing or malformed,
+// are left at their zero values.
+type Resolved struct {
+	Record *Record
+
+	CountryCode    string        // CountryShort in upper case, or "" if invalid
+	NetSpeed       NetSpeedType  // parsed Record.NetSpeed
+	UsageType      UsageType     // parsed Record.UsageType
+	TimeZoneOffset time.Duration // parsed Record.TimeZone
+	TimeZone       *time.Location
+	HasCoordinates bool
+	Latitude       float64
+	Longitude      float64
+	Elevation      float64 // in meters
+}
+
+// ResolveAll gets all fields, like GetAll, and parses those the database
+// carries. It is the most convenient way to use a database; the helpers it
+// combines remain available on Record for finer control. If the address is
+// not in the database, Record is empty and so are the typed fields.
+func (db *DB) ResolveAll(ipaddress string) (*Resolved, error) {
+	x, err := db.query(ipaddress, FieldAll)
+	if err != nil {
+		return nil, err
+	}
+	r := &Resolved{Record: x}
+	if db.countryEnabled {
+		r.CountryCode, _ = x.CountryShortUpper()
+	}
+	if db.netSpeedEnabled {
+		r.NetSpeed = x.NetSpeedType()
+	}
+	if db.usageTypeEnabled {
+		r.UsageType = x.UsageTypeParsed()
+	}
+	if db.timeZoneEnabled {
+		if d, err := x.TimeZoneOffset(); err == nil {
+			r.TimeZoneOffset = d
+			r.TimeZone, _ = x.Location()
+		}
+	}
+	if db.latitudeEnabled && db.longitudeEnabled && x.HasCoordinates() {
+		r.HasCoordinates = true
+		r.Latitude, r.Longitude = x.Coordinates64()
+	}
+	if db.elevationEnabled {
+		r.Elevation = widenFloat32(x.Elevation)
+	}
+	return r, nil
+}
@@ -0,0 +1,200 @@
+package ip2location
+
+import (
+	"math"
+	"math/big"
+	"math/rand"
+	"net/netip"
+	"testing"
+)
+
+// the IPv4 search as it was before search4: big.Int comparisons on every
+// probe; kept to check and benchmark search4 against
+func (db *DB) searchBig4(ipno *big.Int, ipindex uint32) (uint32, bool, error) {
+	var low, mid uint32
+	baseaddr := db.meta.ipv4DatabaseAddr
+	high := db.meta.ipv4DatabaseCount
+	colsize := db.meta.ipv4ColumnsSize
+	if ipindex > 0 {
+		var err error
+		if low, high, err = db.readIndex(4, ipindex); err != nil {
+			return 0, false, err
+		}
+	}
+	if ipno.Cmp(maxIpv4Range) >= 0 {
+		ipno = new(big.Int).Sub(ipno, big.NewInt(1))
+	}
+	for low <= high {
+		mid = low + (high-low)>>1
+		rowoffset := baseaddr + mid*colsize
+		from, err := db.readUint32(rowoffset)
+		if err != nil {
+			return 0, false, err
+		}
+		to, err := db.readUint32(rowoffset + colsize)
+		if err != nil {
+			return 0, false, err
+		}
+		ipfrom, ipto := big.NewInt(int64(from)), big.NewInt(int64(to))
+		if ipno.Cmp(ipfrom) >= 0 && ipno.Cmp(ipto) < 0 {
+			return rowoffset, true, nil
+		}
+		if ipno.Cmp(ipfrom) < 0 {
+			if mid == 0 {
+				break
+			}
+			high = mid - 1
+		} else {
+			low = mid + 1
+		}
+	}
+	return 0, false, nil
+}
+
+func TestSearch4MatchesBigInt(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	for _, index := range []bool{false, true} {
+		d := testDB5
+		d.Index = index
+		db := d.open(t)
+		for i := 0; i < 2000; i++ {
+			n := rnd.Uint32()
+			switch i {
+			case 0:
+				n = 0
+			case 1:
+				n = math.MaxUint32
+			}
+			ipno := new(big.Int).SetUint64(uint64(n))
+			ipindex := db.indexAddr(4, ipno)
+			want, wantFound, err := db.searchBig4(ipno, ipindex)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, _, _, found, err := db.search4(n, ipindex)
+			if err != nil || found != wantFound || got != want {
+				t.Errorf("index %v, %d: search4 got row %d %v %v, big.Int search row %d %v", index, n, got, found, err, want, wantFound)
+			}
+		}
+	}
+}
+
+func BenchmarkSearchIPv4(b *testing.B) {
+	db := testDB5.open(b)
+	ipno := big.NewInt(0x08080808) // 8.8.8.8
+	ipindex := db.indexAddr(4, ipno)
+
+	b.Run("uint32", func(b *testing.B) {
+		b.ReportAllocs()
+		n := uint32(ipno.Uint64())
+		for i := 0; i < b.N; i++ {
+			if _, _, _, found, err := db.search4(n, ipindex); !found || err != nil {
+				b.Fatal(found, err)
+			}
+		}
+	})
+	b.Run("bigint", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, found, err := db.searchBig4(ipno, ipindex); !found || err != nil {
+				b.Fatal(found, err)
+			}
+		}
+	})
+}
+
+// the top address of each family is matched by the last range, however
+// often it is looked up, and the lookups leave the package limits alone
+func TestTopAddress(t *testing.T) {
+	db := testDB5.open(t)
+	maxV4, maxV6 := new(big.Int).Set(maxIpv4Range), new(big.Int).Set(maxIpv6Range)
+	for _, c := range []struct {
+		ip   string
+		want testRange
+	}{
+		{"255.255.255.255", testRangesV4[len(testRangesV4)-1]},
+		{"ffff:ffff:ffff:ffff:ffff:ffff:ffff:ffff", testRangesV6[len(testRangesV6)-1]},
+	} {
+		for range 3 {
+			r, err := db.GetAllRange(c.ip)
+			if err != nil {
+				t.Fatal(c.ip, err)
+			}
+			if r.IPFrom.String() != c.want.From || r.IPTo.String() != c.ip || *r.Record != c.want.Rec {
+				t.Errorf("%s: got %v-%v %+v, want %s-%s %+v", c.ip, r.IPFrom, r.IPTo, *r.Record, c.want.From, c.ip, c.want.Rec)
+			}
+			ipno := addrInt(c.ip)
+			if r.IPFrom.Is4() {
+				ipno = new(big.Int).Set(maxIpv4Range)
+			}
+			orig := new(big.Int).Set(ipno)
+			if x, err := db.GetAllByBigInt(ipno); err != nil || *x != c.want.Rec {
+				t.Errorf("%s: GetAllByBigInt got %v, %v", c.ip, x, err)
+			}
+			if ipno.Cmp(orig) != 0 {
+				t.Errorf("%s: GetAllByBigInt changed its argument to %v", c.ip, ipno)
+			}
+		}
+	}
+	if maxIpv4Range.Cmp(maxV4) != 0 || maxIpv6Range.Cmp(maxV6) != 0 {
+		t.Errorf("lookups changed the limits to %v and %v", maxIpv4Range, maxIpv6Range)
+	}
+}
+
+// the edges of the last range, with and without the index, and lookups in a
+// table the database does not have
+func TestSearchBoundaries(t *testing.T) {
+	lastV4, lastV6 := testRangesV4[len(testRangesV4)-1], testRangesV6[len(testRangesV6)-1]
+	for _, index := range []bool{false, true} {
+		d := testDB5
+		d.Index = index
+		db := d.open(t)
+		for _, c := range []struct {
+			ip, from, to string
+		}{
+			{"8.8.8.255", "8.8.8.0", "8.8.8.255"},
+			{"8.8.9.0", lastV4.From, "255.255.255.255"},
+			{"255.255.255.254", lastV4.From, "255.255.255.255"},
+			{"255.255.255.255", lastV4.From, "255.255.255.255"},
+			{"2400:cb00:ffff:ffff:ffff:ffff:ffff:ffff", "2400:cb00::", "2400:cb00:ffff:ffff:ffff:ffff:ffff:ffff"},
+			{"2400:cb01::", lastV6.From, "ffff:ffff:ffff:ffff:ffff:ffff:ffff:ffff"},
+			{"ffff:ffff:ffff:ffff:ffff:ffff:ffff:fffe", lastV6.From, "ffff:ffff:ffff:ffff:ffff:ffff:ffff:ffff"},
+			{"ffff:ffff:ffff:ffff:ffff:ffff:ffff:ffff", lastV6.From, "ffff:ffff:ffff:ffff:ffff:ffff:ffff:ffff"},
+		} {
+			r, err := db.GetAllRange(c.ip)
+			if err != nil {
+				t.Fatalf("index %v, %s: %v", index, c.ip, err)
+			}
+			if r.IPFrom.String() != c.from || r.IPTo.String() != c.to {
+				t.Errorf("index %v, %s: got %v-%v, want %s-%s", index, c.ip, r.IPFrom, r.IPTo, c.from, c.to)
+			}
+			if ok, err := db.Contains(c.ip); !ok || err != nil {
+				t.Errorf("index %v, %s: Contains got %v, %v", index, c.ip, ok, err)
+			}
+		}
+	}
+
+	// an absent table is a miss, not an error
+	for _, c := range []struct {
+		db testDB
+		ip string
+	}{
+		{testDB{Type: 1, V4: testRangesV4}, "2001:4860::1"},
+		{testDB{Type: 1, V4: testRangesV4, Index: true}, "ffff:ffff:ffff:ffff:ffff:ffff:ffff:ffff"},
+		{testDB{Type: 1, V6: testRangesV6}, "8.8.8.8"},
+		{testDB{Type: 1, V6: testRangesV6, Index: true}, "255.255.255.255"},
+	} {
+		db := c.db.open(t)
+		x, found, err := db.GetAllFound(c.ip)
+		if x != nil || found || err != nil {
+			t.Errorf("%s: got %+v, %v, %v, want a miss", c.ip, x, found, err)
+		}
+		num := db.GetAllByBigInt
+		if a := netip.MustParseAddr(c.ip); a.Is4() {
+			num = func(n *big.Int) (*Record, error) { return db.GetAllByUint32(uint32(n.Uint64())) }
+		}
+		if x, err := num(addrInt(c.ip)); err != nil || *x != (Record{}) {
+			t.Errorf("%s: lookup by number got %+v, %v", c.ip, x, err)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package ip2location
+
+import (
+	"fmt"
+	"net/netip"
+)
+
+// SelfTestCase is a lookup checked by SelfTest.
+type SelfTestCase struct {
+	IP           string
+	CountryShort string // expected country code; "" accepts any non-empty one
+}
+
+// DefaultSelfTestCases are the lookups SelfTest runs when given none. On
+// IP2Proxy databases, which give "-" for addresses that are not proxies, only
+// their addresses are used and any country is accepted.
+var DefaultSelfTestCases = []SelfTestCase{
+	{IP: "8.8.8.8", CountryShort: "US"},
+	{IP: "2001:4860:4860::8888", CountryShort: "US"},
+}
+
+// SelfTest looks up the given addresses, or DefaultSelfTestCases if none are
+// given, and checks that each resolves to the expected country, or to some
+// country if the case expects none. It catches
+// databases that open fine but are corrupt or point at the wrong table, and
+// is meant for readiness probes. IPv6 cases are skipped if the database has
+// no IPv6 table.
+func (db *DB) SelfTest(cases ...SelfTestCase) error {
+	if len(cases) == 0 {
+		cases = DefaultSelfTestCases
+		if db.meta.productCode == productIP2Proxy {
+			cases = make([]SelfTestCase, len(DefaultSelfTestCases))
+			for i, c := range DefaultSelfTestCases {
+				cases[i] = SelfTestCase{IP: c.IP}
+			}
+		}
+	}
+	for _, c := range cases {
+		if addr, err := netip.ParseAddr(c.IP); err == nil && addr.Is6() && !addr.Is4In6() && db.meta.ipv6DatabaseCount == 0 {
+			continue
+		}
+		x, err := db.query(c.IP, FieldCountryShort)
+		if err != nil {
+			return fmt.Errorf("ip2location: self-test %s: %w", c.IP, err)
+		}
+		if x.CountryShort == "" || (c.CountryShort != "" && x.CountryShort != c.CountryShort) {
+			return fmt.Errorf("ip2location: self-test %s: got country %q, want %q", c.IP, x.CountryShort, c.CountryShort)
+		}
+	}
+	return nil
+}
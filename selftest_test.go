@@ -0,0 +1,36 @@
+package ip2location
+
+import "testing"
+
+func TestSelfTest(t *testing.T) {
+	notProxy := Record{CountryShort: "-", CountryLong: "-", ProxyType: "-"}
+	px2 := testDB{Type: 2, Product: productIP2Proxy,
+		V4: []testRange{{From: "0.0.0.0", Rec: notProxy}, {From: "1.0.0.0", Rec: Record{CountryShort: "AU", CountryLong: "Australia", ProxyType: "VPN"}}},
+		V6: []testRange{{From: "::", Rec: notProxy}},
+	}
+	wrong := testDB{Type: 1, V4: []testRange{{From: "0.0.0.0", Rec: Record{CountryShort: "AU", CountryLong: "Australia"}}}}
+	empty := testDB{Type: 1, V4: []testRange{{From: "0.0.0.0", Rec: Record{}}}}
+
+	for _, c := range []struct {
+		name  string
+		db    testDB
+		cases []SelfTestCase
+		ok    bool
+	}{
+		{"DB5 defaults", testDB5, nil, true},
+		{"DB5 IPv4 only", testDB{Type: 5, V4: testRangesV4}, nil, true},
+		{"DB5 custom", testDB5, []SelfTestCase{{IP: "1.0.0.1", CountryShort: "AU"}}, true},
+		{"DB5 mismatch", testDB5, []SelfTestCase{{IP: "1.0.0.1", CountryShort: "CN"}}, false},
+		{"DB5 malformed", testDB5, []SelfTestCase{{IP: "1.0.0"}}, false},
+		{"PX2 defaults", px2, nil, true},
+		{"PX2 custom", px2, []SelfTestCase{{IP: "1.0.0.1", CountryShort: "AU"}}, true},
+		{"PX2 expecting US", px2, []SelfTestCase{{IP: "8.8.8.8", CountryShort: "US"}}, false},
+		{"wrong country", wrong, nil, false},
+		{"empty country", empty, []SelfTestCase{{IP: "8.8.8.8"}}, false},
+	} {
+		err := c.db.open(t).SelfTest(c.cases...)
+		if (err == nil) != c.ok {
+			t.Errorf("%s: got %v, want ok %v", c.name, err, c.ok)
+		}
+	}
+}
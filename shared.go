@@ -0,0 +1,125 @@
+package ip2location
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// identifies an unchanged file opened with the same options
+type sharedKey struct {
+	path    string
+	modTime int64
+	size    int64
+	opts    sharedOptions
+}
+
+// the options a handle was opened with, as comparable values
+type sharedOptions struct {
+	mmap             bool
+	preload          bool
+	indexCache       bool
+	stringCacheSize  int
+	rowMemo          bool
+	strictValidation bool
+	ipv4Embedded     bool
+	readTimeout      time.Duration
+	noIndex          bool
+	byteOrder        string // "" to detect it
+	stats            bool
+	advice           Advice
+	private          bool
+	privateRecord    Record
+	maxStrLen        int
+	centuryBase      int
+}
+
+// the comparable form of o; false if o holds a function or a byte order of
+// its own, which cannot be compared, so that the handle is not shared
+func newSharedOptions(o *options) (sharedOptions, bool) {
+	if o.strDecoder != nil || o.observer != nil {
+		return sharedOptions{}, false
+	}
+	k := sharedOptions{
+		mmap:             o.mmap,
+		preload:          o.preload,
+		indexCache:       o.indexCache,
+		stringCacheSize:  o.stringCacheSize,
+		rowMemo:          o.rowMemo,
+		strictValidation: o.strictValidation,
+		ipv4Embedded:     o.ipv4Embedded,
+		readTimeout:      o.readTimeout,
+		noIndex:          o.noIndex,
+		stats:            o.stats,
+		advice:           o.advice,
+		maxStrLen:        o.maxStrLen,
+		centuryBase:      o.centuryBase,
+	}
+	switch o.byteOrder {
+	case nil:
+	case binary.LittleEndian, binary.BigEndian:
+		k.byteOrder = o.byteOrder.String()
+	default:
+		return sharedOptions{}, false
+	}
+	if o.privateRecord != nil {
+		k.private, k.privateRecord = true, *o.privateRecord
+	}
+	return k, true
+}
+
+// handles opened with WithShared, by file and options
+var shared = struct {
+	mu  sync.Mutex
+	dbs map[sharedKey]*DB
+}{dbs: make(map[sharedKey]*DB)}
+
+// open dbPath with WithShared semantics
+func openShared(dbPath string, o *options) (*DB, error) {
+	opts, ok := newSharedOptions(o)
+	if !ok {
+		return openFile(dbPath, o)
+	}
+	path, err := filepath.Abs(dbPath)
+	if err != nil {
+		return nil, err
+	}
+	fi, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	key := sharedKey{path: path, modTime: fi.ModTime().UnixNano(), size: fi.Size(), opts: opts}
+
+	shared.mu.Lock()
+	defer shared.mu.Unlock()
+
+	if db := shared.dbs[key]; db != nil {
+		if c, err := db.clone(); err == nil { // db itself may have been closed
+			return c, nil
+		}
+		delete(shared.dbs, key) // all handles were closed meanwhile
+	}
+
+	db, err := openFile(path, o)
+	if err != nil {
+		return nil, err
+	}
+	// in-memory databases have nothing to close, but still need counting so
+	// that the entry is dropped once they are all closed
+	rc, ok := db.closer.(*refCloser)
+	if !ok {
+		rc = &refCloser{c: nopCloser{}, refs: 1}
+		db.closer = rc
+	}
+	rc.release = func() {
+		shared.mu.Lock()
+		defer shared.mu.Unlock()
+		if shared.dbs[key] == db {
+			delete(shared.dbs, key)
+		}
+	}
+	shared.dbs[key] = db
+	return db, nil
+}
@@ -0,0 +1,52 @@
+package ip2location
+
+import (
+	"encoding/binary"
+	"testing"
+	"time"
+)
+
+// a byte order that cannot be compared
+type sliceOrder struct {
+	binary.ByteOrder
+	_ []int
+}
+
+func TestSharedOptions(t *testing.T) {
+	path := testDB5.write(t)
+	open := func(opts ...Option) *DB {
+		t.Helper()
+		db, err := Open(path, append(opts, WithShared())...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { db.Close() })
+		return db
+	}
+	sameFile := func(a, b *DB) bool { return a.closer == b.closer }
+
+	for _, c := range []struct {
+		name   string
+		a, b   []Option
+		shared bool
+	}{
+		{"no options", nil, nil, true},
+		{"same options", []Option{WithStringCache(8), WithoutIndex()}, []Option{WithStringCache(8), WithoutIndex()}, true},
+		{"other cache size", []Option{WithStringCache(8)}, []Option{WithStringCache(16)}, false},
+		{"same byte order", []Option{WithByteOrder(binary.LittleEndian)}, []Option{WithByteOrder(binary.LittleEndian)}, true},
+		{"byte order or detected", []Option{WithByteOrder(binary.LittleEndian)}, nil, false},
+		{"own byte order", []Option{WithByteOrder(sliceOrder{ByteOrder: binary.LittleEndian})}, []Option{WithByteOrder(sliceOrder{ByteOrder: binary.LittleEndian})}, false},
+		{"equal private records", []Option{WithPrivateIPShortcut(&Record{CountryShort: "ZZ"})}, []Option{WithPrivateIPShortcut(nil)}, true},
+		{"other private records", []Option{WithPrivateIPShortcut(nil)}, []Option{WithPrivateIPShortcut(&Record{CountryShort: "XX"})}, false},
+		{"private record or none", []Option{WithPrivateIPShortcut(nil)}, nil, false},
+		{"observer", []Option{WithObserver(0, func(string, time.Duration, error) {})}, []Option{WithObserver(0, func(string, time.Duration, error) {})}, false},
+		{"string decoder", []Option{WithStringDecoder(func(b []byte) string { return string(b) })}, []Option{WithStringDecoder(func(b []byte) string { return string(b) })}, false},
+	} {
+		a, b := open(c.a...), open(c.b...)
+		if got := sameFile(a, b); got != c.shared {
+			t.Errorf("%s: shared %v, want %v", c.name, got, c.shared)
+		}
+		a.Close()
+		b.Close()
+	}
+}
@@ -0,0 +1,68 @@
+package ip2location
+
+import "sync/atomic"
+
+// Stats is a snapshot of the counters enabled by WithStats.
+type Stats struct {
+	Lookups          uint64 // searches of the IPv4 or IPv6 table
+	Misses           uint64 // searches that found no range
+	InvalidAddresses uint64 // addresses that could not be parsed
+	Reads            uint64 // ReadAt calls; none for in-memory databases
+	BytesRead        uint64 // bytes requested by those calls
+}
+
+// counters behind Stats; updated atomically
+type stats struct {
+	lookups          uint64
+	misses           uint64
+	invalidAddresses uint64
+	reads            uint64
+	bytesRead        uint64
+}
+
+// Stats returns the current counters. It returns a zero Stats unless the
+// database was opened with WithStats.
+func (db *DB) Stats() Stats {
+	s := db.stats
+	if s == nil {
+		return Stats{}
+	}
+	return Stats{
+		Lookups:          atomic.LoadUint64(&s.lookups),
+		Misses:           atomic.LoadUint64(&s.misses),
+		InvalidAddresses: atomic.LoadUint64(&s.invalidAddresses),
+		Reads:            atomic.LoadUint64(&s.reads),
+		BytesRead:        atomic.LoadUint64(&s.bytesRead),
+	}
+}
+
+// Sub returns the counts accumulated since prev, an earlier snapshot of the
+// same handle. Tests can use it to check how many reads a lookup costs:
+//
+//	before := db.Stats()
+//	db.GetAll(ip)
+//	if reads := db.Stats().Sub(before).Reads; reads > 2 {
+//		t.Errorf("GetAll did %d reads", reads)
+//	}
+//
+// Reads are only counted for file-backed handles, so open the database with
+// Open and WithStats, without WithPreload, and do not share the handle with
+// concurrent lookups meanwhile.
+func (s Stats) Sub(prev Stats) Stats {
+	return Stats{
+		Lookups:          s.Lookups - prev.Lookups,
+		Misses:           s.Misses - prev.Misses,
+		InvalidAddresses: s.InvalidAddresses - prev.InvalidAddresses,
+		Reads:            s.Reads - prev.Reads,
+		BytesRead:        s.BytesRead - prev.BytesRead,
+	}
+}
+
+// ReadAt on the backing reader, counted if stats are enabled
+func (db *DB) readAt(p []byte, off int64) (int, error) {
+	if db.stats != nil {
+		atomic.AddUint64(&db.stats.reads, 1)
+		atomic.AddUint64(&db.stats.bytesRead, uint64(len(p)))
+	}
+	return db.file.ReadAt(p, off)
+}
@@ -0,0 +1,27 @@
+package ip2location
+
+import "testing"
+
+// GetAll reads the row once and then each string, with a length and a body
+// read; the search of the 7 rows of each table takes at most 6 more
+func TestGetAllReads(t *testing.T) {
+	const (
+		strs    = 18 // DB24: the country code and name and 16 other fields
+		maxRead = 6 + 1 + 2*strs
+	)
+	for _, d := range []testDB{
+		{Type: 24, V4: testRangesV4, V6: testRangesV6},
+		{Type: 24, V4: testRangesV4, V6: testRangesV6, Index: true},
+	} {
+		db := d.openFile(t, WithStats())
+		for _, ip := range []string{"8.8.8.8", "2001:4860::1"} {
+			before := db.Stats()
+			if _, err := db.GetAll(ip); err != nil {
+				t.Fatal(err)
+			}
+			if reads := db.Stats().Sub(before).Reads; reads > maxRead {
+				t.Errorf("index %v: GetAll(%s) did %d reads, want at most %d", d.Index, ip, reads, maxRead)
+			}
+		}
+	}
+}
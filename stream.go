@@ -0,0 +1,100 @@
+package ip2location
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// number of lines written between flushes in ResolveStream
+const streamFlushLines = 1024
+
+// ResolveStream reads newline-delimited IP addresses from r, looks each up and
+// writes one tab-separated line per address to w: the address followed by the
+// requested fields, in the order of the Field constants. Tabs, line breaks and
+// backslashes in values are escaped as \t, \n, \r and \\. Blank lines are
+// skipped. Malformed addresses are skipped too and reported once the stream
+// ends, in an errors.Join of one error per line, each wrapping
+// ErrInvalidAddress and naming the line. Read, write and lookup errors stop
+// the stream.
+func (db *DB) ResolveStream(r io.Reader, w io.Writer, fields Field) error {
+	sc := bufio.NewScanner(r)
+	bw := bufio.NewWriter(w)
+	x := &Record{}
+	var out []byte
+	var invalid []error
+
+	for n, pending := 1, 0; sc.Scan(); n++ {
+		ip := strings.TrimSpace(sc.Text())
+		if ip == "" {
+			continue
+		}
+
+		if err := db.QueryInto(ip, fields, x); err != nil {
+			if err == ErrInvalidAddress {
+				invalid = append(invalid, fmt.Errorf("line %d: %q: %w", n, ip, err))
+				continue
+			}
+			bw.Flush()
+			return fmt.Errorf("line %d: %w", n, err)
+		}
+
+		out = appendTSV(append(out[:0], ip...), x, fields)
+		if _, err := bw.Write(out); err != nil {
+			return err
+		}
+		if pending++; pending == streamFlushLines {
+			if err := bw.Flush(); err != nil {
+				return err
+			}
+			pending = 0
+		}
+	}
+	if err := sc.Err(); err != nil {
+		bw.Flush()
+		return err
+	}
+	if err := bw.Flush(); err != nil {
+		return err
+	}
+	return errors.Join(invalid...)
+}
+
+// append the requested fields of x to b, each preceded by a tab, followed by
+// a newline
+func appendTSV(b []byte, x *Record, fields Field) []byte {
+	for _, e := range fieldNames {
+		if fields&e.field == 0 {
+			continue
+		}
+		b = append(b, '\t')
+		if str, f := x.fieldPtr(e.field); str != nil {
+			b = appendEscaped(b, *str)
+		} else {
+			b = strconv.AppendFloat(b, float64(*f), 'f', -1, 32)
+		}
+	}
+	return append(b, '\n')
+}
+
+// append s to b, escaping the characters that would break a TSV line
+func appendEscaped(b []byte, s string) []byte {
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '\\':
+			b = append(b, '\\', '\\')
+		case '\t':
+			b = append(b, '\\', 't')
+		case '\n':
+			b = append(b, '\\', 'n')
+		case '\r':
+			b = append(b, '\\', 'r')
+		default:
+			b = append(b, c)
+		}
+	}
+	return b
+}
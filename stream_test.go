@@ -0,0 +1,43 @@
+package ip2location
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestResolveStream(t *testing.T) {
+	d := testDB{Type: 3, V4: []testRange{
+		{From: "0.0.0.0", Rec: Record{CountryShort: "-", CountryLong: "-", Region: "-", City: "-"}},
+		{From: "1.0.0.0", Rec: Record{CountryShort: "AU", CountryLong: "Australia", Region: "Queensland", City: "Bris\tbane\\\r\n"}},
+		{From: "1.0.1.0", Rec: Record{CountryShort: "CN", CountryLong: "China", Region: "Fujian", City: "Fuzhou"}},
+	}}
+	db := d.open(t)
+
+	in := "1.0.0.5\n\n  1.0.1.7 \nbogus\n1.0.1.8\n1.2.3\n"
+	var out strings.Builder
+	err := db.ResolveStream(strings.NewReader(in), &out, FieldCountryShort|FieldCity)
+
+	want := "1.0.0.5\tAU\tBris\\tbane\\\\\\r\\n\n" +
+		"1.0.1.7\tCN\tFuzhou\n" +
+		"1.0.1.8\tCN\tFuzhou\n"
+	if out.String() != want {
+		t.Errorf("got output\n%q\nwant\n%q", out.String(), want)
+	}
+	if !errors.Is(err, ErrInvalidAddress) {
+		t.Fatalf("got error %v, want one wrapping ErrInvalidAddress", err)
+	}
+	for _, line := range []string{`line 4: "bogus"`, `line 6: "1.2.3"`} {
+		if !strings.Contains(err.Error(), line) {
+			t.Errorf("error %q does not report %s", err, line)
+		}
+	}
+
+	out.Reset()
+	if err := db.ResolveStream(strings.NewReader("1.0.1.7\n"), &out, FieldRegion); err != nil {
+		t.Errorf("clean stream: %v", err)
+	}
+	if out.String() != "1.0.1.7\tFujian\n" {
+		t.Errorf("clean stream: got %q", out.String())
+	}
+}
@@ -0,0 +1,45 @@
+package ip2location
+
+import (
+	"fmt"
+	"time"
+)
+
+// TimeZoneOffset parses TimeZone, a UTC offset such as "+08:00", "-05:00" or
+// "+05:45", into a signed duration. An empty or malformed value, including
+// the placeholder "-", gives an error.
+func (x *Record) TimeZoneOffset() (time.Duration, error) {
+	tz := x.TimeZone
+	if len(tz) != 6 || tz[0] != '+' && tz[0] != '-' || tz[3] != ':' {
+		return 0, fmt.Errorf("ip2location: invalid time zone %q", tz)
+	}
+	h, ok1 := parse2(tz[1:3])
+	m, ok2 := parse2(tz[4:6])
+	if !ok1 || !ok2 || h > 14 || m > 59 {
+		return 0, fmt.Errorf("ip2location: invalid time zone %q", tz)
+	}
+	d := time.Duration(h)*time.Hour + time.Duration(m)*time.Minute
+	if tz[0] == '-' {
+		d = -d
+	}
+	return d, nil
+}
+
+// Location returns a fixed zone for TimeZone, named after it, e.g.
+// "UTC+08:00". It does not follow daylight saving time, which the database
+// does not describe.
+func (x *Record) Location() (*time.Location, error) {
+	d, err := x.TimeZoneOffset()
+	if err != nil {
+		return nil, err
+	}
+	return time.FixedZone("UTC"+x.TimeZone, int(d/time.Second)), nil
+}
+
+// parse two decimal digits
+func parse2(s string) (int, bool) {
+	if s[0] < '0' || s[0] > '9' || s[1] < '0' || s[1] > '9' {
+		return 0, false
+	}
+	return int(s[0]-'0')*10 + int(s[1]-'0'), true
+}
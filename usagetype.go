@@ -0,0 +1,126 @@
+package ip2location
+
+// UsageType is the parsed form of Record.UsageType.
+//
+// The documented IP2Location codes are:
+//
+//	COM      Commercial
+//	ORG      Organization
+//	GOV      Government
+//	MIL      Military
+//	EDU      University/College/School
+//	LIB      Library
+//	CDN      Content Delivery Network
+//	ISP      Fixed Line ISP
+//	MOB      Mobile ISP
+//	ISP/MOB  Fixed Line and Mobile ISP
+//	DCH      Data Center/Web Hosting/Transit
+//	SES      Search Engine Spider
+//	RSV      Reserved
+type UsageType uint8
+
+const (
+	UsageUnknown      UsageType = iota // empty or unrecognized code
+	UsageCommercial                    // COM
+	UsageOrganization                  // ORG
+	UsageGovernment                    // GOV
+	UsageMilitary                      // MIL
+	UsageEducation                     // EDU
+	UsageLibrary                       // LIB
+	UsageCDN                           // CDN
+	UsageISP                           // ISP
+	UsageMobile                        // MOB
+	UsageISPMobile                     // ISP/MOB
+	UsageDataCenter                    // DCH
+	UsageSearchEngine                  // SES
+	UsageReserved                      // RSV
+)
+
+var usageTypes = [...]struct {
+	code        string
+	description string
+}{
+	UsageUnknown:      {"", "Unknown"},
+	UsageCommercial:   {"COM", "Commercial"},
+	UsageOrganization: {"ORG", "Organization"},
+	UsageGovernment:   {"GOV", "Government"},
+	UsageMilitary:     {"MIL", "Military"},
+	UsageEducation:    {"EDU", "University/College/School"},
+	UsageLibrary:      {"LIB", "Library"},
+	UsageCDN:          {"CDN", "Content Delivery Network"},
+	UsageISP:          {"ISP", "Fixed Line ISP"},
+	UsageMobile:       {"MOB", "Mobile ISP"},
+	UsageISPMobile:    {"ISP/MOB", "Fixed Line and Mobile ISP"},
+	UsageDataCenter:   {"DCH", "Data Center/Web Hosting/Transit"},
+	UsageSearchEngine: {"SES", "Search Engine Spider"},
+	UsageReserved:     {"RSV", "Reserved"},
+}
+
+// ParseUsageType maps a raw usage type code to its UsageType.
+// Unrecognized codes map to UsageUnknown.
+func ParseUsageType(code string) UsageType {
+	for i := range usageTypes {
+		if i != int(UsageUnknown) && usageTypes[i].code == code {
+			return UsageType(i)
+		}
+	}
+	return UsageUnknown
+}
+
+// String returns the raw IP2Location code for t, or "" if unknown.
+func (t UsageType) String() string {
+	if int(t) >= len(usageTypes) {
+		return ""
+	}
+	return usageTypes[t].code
+}
+
+// Description returns a human-readable description of t.
+func (t UsageType) Description() string {
+	if int(t) >= len(usageTypes) {
+		return usageTypes[UsageUnknown].description
+	}
+	return usageTypes[t].description
+}
+
+// UsageTypeParsed returns the parsed usage type. The raw code is kept in
+// UsageType so that codes unknown to this package are not lost.
+func (x *Record) UsageTypeParsed() UsageType {
+	return ParseUsageType(x.UsageType)
+}
+
+// HostingUsageTypes are the usage types for which IsLikelyHosting reports
+// true. They may be changed at init time to tune the heuristic.
+var HostingUsageTypes = []UsageType{UsageDataCenter, UsageCDN}
+
+// MobileUsageTypes are the usage types for which IsLikelyMobile reports true.
+// They may be changed at init time to tune the heuristic.
+var MobileUsageTypes = []UsageType{UsageMobile, UsageISPMobile}
+
+// IsLikelyHosting reports whether the usage type is one of HostingUsageTypes,
+// by default DCH or CDN. Such addresses are commonly proxies or VPN exits.
+// It is false when the database has no usage type column. Net speed is not
+// considered, as none of its codes separates hosting from other traffic.
+func (x *Record) IsLikelyHosting() bool {
+	return x.usageTypeIn(HostingUsageTypes)
+}
+
+// IsLikelyMobile reports whether the usage type is one of MobileUsageTypes,
+// by default MOB or ISP/MOB. It is false when the database has no usage type
+// column.
+func (x *Record) IsLikelyMobile() bool {
+	return x.usageTypeIn(MobileUsageTypes)
+}
+
+func (x *Record) usageTypeIn(types []UsageType) bool {
+	t := x.UsageTypeParsed()
+	if t == UsageUnknown {
+		return false
+	}
+	for _, u := range types {
+		if t == u {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,195 @@
+package ip2location
+
+// The methods below return a single field as a plain value. Like the Get
+// methods, they return the zero value when the address is not found and
+// ErrFieldUnsupported when the database does not carry the field.
+
+// CountryShort returns the two-character country code for the IP address.
+func (db *DB) CountryShort(ipaddress string) (string, error) {
+	x, err := db.queryField(ipaddress, FieldCountryShort)
+	if err != nil {
+		return "", err
+	}
+	return x.CountryShort, nil
+}
+
+// CountryLong returns the country name for the IP address.
+func (db *DB) CountryLong(ipaddress string) (string, error) {
+	x, err := db.queryField(ipaddress, FieldCountryLong)
+	if err != nil {
+		return "", err
+	}
+	return x.CountryLong, nil
+}
+
+// Region returns the region or state name for the IP address.
+func (db *DB) Region(ipaddress string) (string, error) {
+	x, err := db.queryField(ipaddress, FieldRegion)
+	if err != nil {
+		return "", err
+	}
+	return x.Region, nil
+}
+
+// City returns the city name for the IP address.
+func (db *DB) City(ipaddress string) (string, error) {
+	x, err := db.queryField(ipaddress, FieldCity)
+	if err != nil {
+		return "", err
+	}
+	return x.City, nil
+}
+
+// ISP returns the ISP name for the IP address.
+func (db *DB) ISP(ipaddress string) (string, error) {
+	x, err := db.queryField(ipaddress, FieldISP)
+	if err != nil {
+		return "", err
+	}
+	return x.Isp, nil
+}
+
+// Domain returns the domain name for the IP address.
+func (db *DB) Domain(ipaddress string) (string, error) {
+	x, err := db.queryField(ipaddress, FieldDomain)
+	if err != nil {
+		return "", err
+	}
+	return x.Domain, nil
+}
+
+// ZipCode returns the ZIP or postal code for the IP address.
+func (db *DB) ZipCode(ipaddress string) (string, error) {
+	x, err := db.queryField(ipaddress, FieldZipCode)
+	if err != nil {
+		return "", err
+	}
+	return x.Zipcode, nil
+}
+
+// TimeZone returns the UTC offset for the IP address.
+func (db *DB) TimeZone(ipaddress string) (string, error) {
+	x, err := db.queryField(ipaddress, FieldTimeZone)
+	if err != nil {
+		return "", err
+	}
+	return x.TimeZone, nil
+}
+
+// NetSpeed returns the raw net speed code for the IP address.
+func (db *DB) NetSpeed(ipaddress string) (string, error) {
+	x, err := db.queryField(ipaddress, FieldNetSpeed)
+	if err != nil {
+		return "", err
+	}
+	return x.NetSpeed, nil
+}
+
+// IDDCode returns the international dialing code for the IP address.
+func (db *DB) IDDCode(ipaddress string) (string, error) {
+	x, err := db.queryField(ipaddress, FieldIDDCode)
+	if err != nil {
+		return "", err
+	}
+	return x.IddCode, nil
+}
+
+// AreaCode returns the area code for the IP address.
+func (db *DB) AreaCode(ipaddress string) (string, error) {
+	x, err := db.queryField(ipaddress, FieldAreaCode)
+	if err != nil {
+		return "", err
+	}
+	return x.Areacode, nil
+}
+
+// WeatherStationCode returns the weather station code for the IP address.
+func (db *DB) WeatherStationCode(ipaddress string) (string, error) {
+	x, err := db.queryField(ipaddress, FieldWeatherStationCode)
+	if err != nil {
+		return "", err
+	}
+	return x.WeatherStationCode, nil
+}
+
+// WeatherStationName returns the weather station name for the IP address.
+func (db *DB) WeatherStationName(ipaddress string) (string, error) {
+	x, err := db.queryField(ipaddress, FieldWeatherStationName)
+	if err != nil {
+		return "", err
+	}
+	return x.WeatherStationName, nil
+}
+
+// MCC returns the mobile country code for the IP address.
+func (db *DB) MCC(ipaddress string) (string, error) {
+	x, err := db.queryField(ipaddress, FieldMCC)
+	if err != nil {
+		return "", err
+	}
+	return x.Mcc, nil
+}
+
+// MNC returns the mobile network code for the IP address.
+func (db *DB) MNC(ipaddress string) (string, error) {
+	x, err := db.queryField(ipaddress, FieldMNC)
+	if err != nil {
+		return "", err
+	}
+	return x.Mnc, nil
+}
+
+// MobileBrand returns the mobile carrier brand for the IP address.
+func (db *DB) MobileBrand(ipaddress string) (string, error) {
+	x, err := db.queryField(ipaddress, FieldMobileBrand)
+	if err != nil {
+		return "", err
+	}
+	return x.MobileBrand, nil
+}
+
+// UsageType returns the raw usage type code for the IP address.
+func (db *DB) UsageType(ipaddress string) (string, error) {
+	x, err := db.queryField(ipaddress, FieldUsageType)
+	if err != nil {
+		return "", err
+	}
+	return x.UsageType, nil
+}
+
+// Latitude returns the latitude for the IP address.
+func (db *DB) Latitude(ipaddress string) (float32, error) {
+	x, err := db.queryField(ipaddress, FieldLatitude)
+	if err != nil {
+		return 0, err
+	}
+	return x.Latitude, nil
+}
+
+// Longitude returns the longitude for the IP address.
+func (db *DB) Longitude(ipaddress string) (float32, error) {
+	x, err := db.queryField(ipaddress, FieldLongitude)
+	if err != nil {
+		return 0, err
+	}
+	return x.Longitude, nil
+}
+
+// Elevation returns the elevation in meters for the IP address.
+func (db *DB) Elevation(ipaddress string) (float32, error) {
+	x, err := db.queryField(ipaddress, FieldElevation)
+	if err != nil {
+		return 0, err
+	}
+	return x.Elevation, nil
+}
+
+// Coordinates returns the latitude and longitude for the IP address with a
+// single search.
+func (db *DB) Coordinates(ipaddress string) (lat, lon float32, err error) {
+	x, err := db.queryField(ipaddress, FieldLatitude|FieldLongitude)
+	if err != nil {
+		return 0, 0, err
+	}
+	return x.Latitude, x.Longitude, nil
+}
@@ -0,0 +1,16 @@
+package ip2location
+
+import "testing"
+
+func TestFieldUnsupported(t *testing.T) {
+	db1 := testDB{Type: 1, V4: testRangesV4}.open(t)
+	if country, err := db1.CountryShort("8.8.8.8"); err != nil || country != "US" {
+		t.Errorf("CountryShort: got %q, %v", country, err)
+	}
+	if _, err := db1.City("8.8.8.8"); err != ErrFieldUnsupported {
+		t.Errorf("City on DB1: got %v, want ErrFieldUnsupported", err)
+	}
+	if _, _, err := db1.Coordinates("8.8.8.8"); err != ErrFieldUnsupported {
+		t.Errorf("Coordinates on DB1: got %v, want ErrFieldUnsupported", err)
+	}
+}
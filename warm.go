@@ -0,0 +1,52 @@
+package ip2location
+
+import (
+	"io"
+	"sync/atomic"
+)
+
+// size of the reads issued by Warm
+const warmChunk = 1 << 20
+
+// Warm reads the index tables, unless they are cached, and the IPv4 and IPv6
+// tables once, so that their pages are in the OS page cache, or faulted into
+// the mapping with WithMmap, before the first lookup. Strings are not read.
+// Warm may be called while the database is in use; it is a no-op for
+// in-memory databases and once it has succeeded on any clone of the handle.
+// Its reads are not counted by Stats.
+func (db *DB) Warm() error {
+	if db.data != nil || atomic.LoadUint32(&db.meta.warmed) != 0 {
+		return nil
+	}
+	m := db.meta
+
+	var ranges [4][2]int64
+	if db.ipv4Index == nil && m.ipv4IndexBaseAddr > 0 {
+		ranges[0] = [2]int64{int64(m.ipv4IndexBaseAddr) - 1, indexSize}
+	}
+	if db.ipv6Index == nil && m.ipv6IndexBaseAddr > 0 {
+		ranges[1] = [2]int64{int64(m.ipv6IndexBaseAddr) - 1, indexSize}
+	}
+	if m.ipv4DatabaseCount > 0 {
+		ranges[2] = [2]int64{int64(m.ipv4DatabaseAddr) - 1, int64(m.ipv4DatabaseCount+1) * int64(m.ipv4ColumnsSize)}
+	}
+	if m.ipv6DatabaseCount > 0 {
+		ranges[3] = [2]int64{int64(m.ipv6DatabaseAddr) - 1, int64(m.ipv6DatabaseCount+1) * int64(m.ipv6ColumnSize)}
+	}
+
+	buf := make([]byte, warmChunk)
+	for _, r := range ranges {
+		for off, end := r[0], r[0]+r[1]; off < end; off += warmChunk {
+			p := buf
+			if end-off < warmChunk {
+				p = buf[:end-off]
+			}
+			// the last table may end with a partial sentinel row
+			if _, err := db.file.ReadAt(p, off); err != nil && err != io.EOF {
+				return readErr(off, err)
+			}
+		}
+	}
+	atomic.StoreUint32(&db.meta.warmed, 1)
+	return nil
+}